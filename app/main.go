@@ -2,32 +2,142 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"slices"
+	"time"
 
 	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+	"smuggr.xyz/arrango/core/server"
 	"smuggr.xyz/arrango/core/solver"
 )
 
+// The formats the solved timetables can be written in
+var formats = []string{"json", "csv", "html", "ics", "text"}
+
 func main() {
-	solver := solver.Solver{
-		PopulationSize: 50,
-		Generations:    1000,
-		MutationRate:   0.1,
+	inputPath := flag.String("input", "", "Path to a JSON file with the input data, the example data is used if empty")
+	configPath := flag.String("config", "", "Path to a JSON file with the solver parameters, the defaults are used if empty")
+	serveAddr := flag.String("serve", "", "Address to serve the HTTP solving service on, e.g. :8080, instead of solving the input once")
+	format := flag.String("format", "json", "Format of the solved timetables, one of json, csv, html, ics or text")
+	outPath := flag.String("out", "-", "Path of the file to write the solved timetables to, - means stdout")
+	division := flag.Int("division", 0, "Index of the division written with -format ics, a calendar holds a single division")
+	budget := flag.Duration("budget", 0, "Time to solve for instead of the configured generations, e.g. 10s, 0 means the generations are run")
+	flag.Parse()
+
+	if !slices.Contains(formats, *format) {
+		log.Fatalf("Error parsing flags: unknown format %q, expected one of %v", *format, formats)
+	}
+
+	in := input.ExampleInputData
+	if *inputPath != "" {
+		var err error
+		in, err = input.LoadFromFile(*inputPath)
+		if err != nil {
+			log.Fatalf("Error loading input data: %v", err)
+		}
 	}
-	result := solver.Solve(input.ExampleInputData)
 
-	jsonResult, err := json.Marshal(result)
+	cfg := solver.DefaultConfig
+	if *configPath != "" {
+		var err error
+		cfg, err = solver.LoadConfig(*configPath)
+		if err != nil {
+			log.Fatalf("Error loading solver config: %v", err)
+		}
+	}
+
+	solver, err := solver.New(cfg)
 	if err != nil {
-		log.Fatalf("Error converting result to JSON: %v", err)
+		log.Fatalf("Error creating solver: %v", err)
 	}
 
-	fmt.Println("Result:", string(jsonResult))
-	filePath := "/home/karol/Documents/Repositories/Arrango/web/mock/public/timetables.json"
-	err = os.WriteFile(filePath, jsonResult, 0644)
+	if *serveAddr != "" {
+		log.Printf("Serving on %s", *serveAddr)
+		log.Fatalf("Error serving: %v", http.ListenAndServe(*serveAddr, server.Handler(*solver)))
+	}
+	for _, e := range in.Validate() {
+		fmt.Fprintln(os.Stderr, "warning:", e)
+	}
+	if ok, reasons := solver.FeasibilityCheck(in); !ok {
+		for _, reason := range reasons {
+			fmt.Fprintln(os.Stderr, "infeasible:", reason)
+		}
+		os.Exit(1)
+	}
+
+	solved := solve(solver, in, *budget)
+	if solved.Err != nil {
+		log.Fatalf("Error solving: %v", solved.Err)
+	}
+	result := solved.Output
+
+	jsonReport, err := json.Marshal(solved.Report)
 	if err != nil {
-		log.Fatalf("Error writing result to file: %v", err)
+		log.Fatalf("Error converting report to JSON: %v", err)
+	}
+	fmt.Fprintln(os.Stderr, "Report:", string(jsonReport))
+
+	if err := writeResult(*outPath, *format, result, in, *division); err != nil {
+		log.Fatalf("Error writing result: %v", err)
+	}
+
+	if solver.WarnInfeasible(os.Stderr, result, in) {
+		os.Exit(1)
+	}
+}
+
+// solve solves the input for the time budget, or for the configured generations when it's 0.
+func solve(s *solver.Solver, in input.InputData, budget time.Duration) solver.Result {
+	if budget > 0 {
+		return s.SolveWithBudget(in, budget)
+	}
+	return s.SolveContext(context.Background(), in)
+}
+
+// writeResult writes the solved timetables in the given format to the file at path,
+// or to stdout when path is -.
+func writeResult(path, format string, result output.OutputData, in input.InputData, division int) error {
+	if path == "-" {
+		return writeFormat(os.Stdout, format, result, in, division)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeFormat(f, format, result, in, division); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeFormat writes the solved timetables to w in the given format, the ics calendar
+// holds the given division for the current week.
+func writeFormat(w io.Writer, format string, result output.OutputData, in input.InputData, division int) error {
+	switch format {
+	case "json":
+		return json.NewEncoder(w).Encode(result)
+	case "csv":
+		return output.WriteCSV(w, result, in)
+	case "html":
+		return output.RenderHTML(w, result, in)
+	case "ics":
+		now := time.Now()
+		monday := now.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+		return output.WriteICS(w, result, in, division, monday)
+	case "text":
+		_, err := io.WriteString(w, output.Format(result, in))
+		return err
+	default:
+		return fmt.Errorf("unknown format %q, expected one of %v", format, formats)
 	}
 }
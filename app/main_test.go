@@ -0,0 +1,29 @@
+// app/main_test.go
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/core/solver"
+)
+
+func TestWriteFormat(t *testing.T) {
+	in := input.ExampleInputData
+	s := &solver.Solver{PopulationSize: 10, Generations: 5, MutationRate: 0.1, Seed: 1}
+	result := s.Solve(in)
+
+	for _, format := range formats {
+		var buf bytes.Buffer
+		if err := writeFormat(&buf, format, result, in, 0); err != nil {
+			t.Errorf("%s: %v", format, err)
+		} else if buf.Len() == 0 {
+			t.Errorf("%s: nothing was written", format)
+		}
+	}
+
+	if err := writeFormat(&bytes.Buffer{}, "pdf", result, in, 0); err == nil {
+		t.Error("an unknown format isn't rejected")
+	}
+}
@@ -0,0 +1,35 @@
+// common/models/input/enums.go
+package input
+
+import (
+	"fmt"
+	"slices"
+)
+
+// AllPlacements returns every valid subject placement.
+func AllPlacements() []SubjectPlacementType {
+	return []SubjectPlacementType{SubjectPlacementAny, SubjectPlacementEdges, SubjectPlacementCenter}
+}
+
+// AllGroups returns every valid subjects group.
+func AllGroups() []SubjectsGroupType {
+	return []SubjectsGroupType{SubjectsGroupNone, SubjectsGroupOne, SubjectsGroupTwo, SubjectsGroupThree, SubjectsGroupFour}
+}
+
+// ParsePlacement parses a subject placement from its string value.
+func ParsePlacement(s string) (SubjectPlacementType, error) {
+	placement := SubjectPlacementType(s)
+	if !slices.Contains(AllPlacements(), placement) {
+		return "", fmt.Errorf("unknown placement %q, expected one of %v", s, AllPlacements())
+	}
+	return placement, nil
+}
+
+// ParseGroup parses a subjects group from its string value.
+func ParseGroup(s string) (SubjectsGroupType, error) {
+	group := SubjectsGroupType(s)
+	if !slices.Contains(AllGroups(), group) {
+		return "", fmt.Errorf("unknown group %q, expected one of %v", s, AllGroups())
+	}
+	return group, nil
+}
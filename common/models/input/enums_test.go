@@ -0,0 +1,32 @@
+// common/models/input/enums_test.go
+package input
+
+import (
+	"testing"
+)
+
+func TestParsePlacement(t *testing.T) {
+	for _, placement := range AllPlacements() {
+		if got, err := ParsePlacement(string(placement)); err != nil || got != placement {
+			t.Errorf("ParsePlacement(%q) = %q, %v", placement, got, err)
+		}
+	}
+	for _, s := range []string{"", "center", "Edges", "any "} {
+		if _, err := ParsePlacement(s); err == nil {
+			t.Errorf("ParsePlacement(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestParseGroup(t *testing.T) {
+	for _, group := range AllGroups() {
+		if got, err := ParseGroup(string(group)); err != nil || got != group {
+			t.Errorf("ParseGroup(%q) = %q, %v", group, got, err)
+		}
+	}
+	for _, s := range []string{"", "4", "five", "One"} {
+		if _, err := ParseGroup(s); err == nil {
+			t.Errorf("ParseGroup(%q) succeeded, want an error", s)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 // common/models/input/input.go
 package input
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 /* Definitions
 Division: A division is a group of students, with each division having a set
 of subjects that need to be scheduled, each division has a weight that determines how important it is
@@ -66,41 +72,203 @@ const (
 	SubjectsGroupOne     SubjectsGroupType = "one"
 	SubjectsGroupTwo     SubjectsGroupType = "two"
 	SubjectsGroupThree   SubjectsGroupType = "three"
+	SubjectsGroupFour    SubjectsGroupType = "four"
 )
 
 type GlobalSubject string
-type Classroom string
+
+// Properties of a global subject, shared by every division that is taught it
+type SubjectInfo struct {
+	// Whether the subject should be taught early in the day, the same as a Difficulty of 1
+	// for subjects without one
+	PreferMorning bool `json:"prefer_morning,omitempty"`
+	// How demanding the subject is, every hour of it is penalized by how late in the day
+	// it's taught times its difficulty, 0 means it can be taught at any time
+	Difficulty    uint `json:"difficulty,omitempty"`
+}
+
+// A room that a subject is taught in, in JSON it's either a bare name or an object with a capacity or a building
+type Classroom struct {
+	Name     string `json:"name"`
+	// The number of students that fit into the classroom, 0 means unlimited
+	Capacity uint   `json:"capacity,omitempty"`
+	// The building the classroom is in, a teacher shouldn't have to change buildings between
+	// two consecutive slots, empty means it's close to every other classroom
+	Building string `json:"building,omitempty"`
+}
+
+func (c Classroom) String() string {
+	return c.Name
+}
+
+func (c Classroom) MarshalJSON() ([]byte, error) {
+	if c.Capacity == 0 && c.Building == "" {
+		return json.Marshal(c.Name)
+	}
+	type classroom Classroom
+	return json.Marshal(classroom(c))
+}
+
+func (c *Classroom) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*c = Classroom{}
+		return json.Unmarshal(data, &c.Name)
+	}
+	type classroom Classroom
+	return json.Unmarshal(data, (*classroom)(c))
+}
 type Teacher string
 
+// Constraints of a single teacher, shared by all divisions that the teacher teaches
+type TeacherConstraints struct {
+	// The minimum and maximum number of distinct days the teacher should work, 0 means no limit
+	MinWorkDays uint `json:"min_work_days,omitempty"`
+	MaxWorkDays uint `json:"max_work_days,omitempty"`
+	// The global subjects the teacher is qualified to teach, empty means any subject
+	Qualifications []GlobalSubject `json:"qualifications,omitempty"`
+	// The slots in which the teacher can't teach, e.g. because of another school or a meeting
+	Unavailable    []BlockedSlot   `json:"unavailable,omitempty"`
+	// The maximum number of hours the teacher should teach in a day and in a week across all divisions, 0 means no limit
+	MaxHoursPerDay  uint `json:"max_hours_per_day,omitempty"`
+	MaxHoursPerWeek uint `json:"max_hours_per_week,omitempty"`
+	// The names of the divisions the teacher would rather teach, teaching any other division is
+	// only penalized, and not for subjects that name the teacher, empty means no preference
+	PreferredDivisions []string `json:"preferred_divisions,omitempty"`
+}
+
+// A single slot of the week, both indexed from 0, the day from Monday
+type BlockedSlot struct {
+	Day  int `json:"day"`
+	Slot int `json:"slot"`
+}
+
+// A time of day counted from midnight, in JSON it's a "15:04" string
+type ClockTime time.Duration
+
+func (t ClockTime) String() string {
+	return fmt.Sprintf("%02d:%02d", int(time.Duration(t).Hours()), int(time.Duration(t).Minutes())%60)
+}
+
+func (t ClockTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *ClockTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.Parse("15:04", s)
+	if err != nil {
+		return fmt.Errorf("invalid clock time %q, expected HH:MM", s)
+	}
+	*t = ClockTime(time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute)
+	return nil
+}
+
+// The clock times of a slot, slots may differ in length, e.g. a 45 minute lesson followed by a 90 minute block
+type TimeSlot struct {
+	Start ClockTime `json:"start"`
+	End   ClockTime `json:"end"`
+}
+
+// The blocks of a subject in a week, each entry is the number of consecutive hours of a block
+// placed on a day of its own, zero entries are unused, so [1, 0, 0, 0, 0] is a single hour,
+// in JSON it's a list of at most 5 entries without the trailing zeros, e.g. [1] or [0, 2, 1],
+// so it round-trips exactly, an all-zero allocation is an empty list
+type Allocation [5]uint
+
+func (a Allocation) MarshalJSON() ([]byte, error) {
+	n := len(a)
+	for n > 0 && a[n-1] == 0 {
+		n--
+	}
+	return json.Marshal(a[:n])
+}
+
+func (a *Allocation) UnmarshalJSON(data []byte) error {
+	var blocks []uint
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	if len(blocks) > len(a) {
+		return fmt.Errorf("allocation has %d blocks, but at most %d fit into a week", len(blocks), len(a))
+	}
+	*a = Allocation{}
+	copy(a[:], blocks)
+	return nil
+}
+
 type Subject struct {
-	GlobalSubject *GlobalSubject       `json:"global_subject,omitempty"`
+	GlobalSubject       *GlobalSubject       `json:"global_subject,omitempty"`
 	// The number of consecutive hours that the subject should be placed in the timetable, indexed by the day of the week,
 	// e.g. [2, 1, 2, 1, 2] means that the subject should be placed in two consecutive hours on any day of the week, one hour
 	// on any other day of the week, two consecutive hours on any day of the week, one hour on any other day of the week,
 	// and two consecutive hours on any day of the week, respectively, it can't be placed in the same day twice
 	// e.g. [2, 1] means that the subject should be placed in two consecutive hours on any day of the week and one hour on any other day of the week
-	Allocation    [5]uint              `json:"allocation,omitempty"`
+	// An Allocation is always written, since omitempty never applies to arrays
+	Allocation          Allocation           `json:"allocation"`
 	// Determines where the subject should be placed in the timetable
-	Placement     SubjectPlacementType `json:"placement,omitempty"`
+	Placement           SubjectPlacementType `json:"placement,omitempty"`
 	// The teacher that should teach the subject in that division
-	Teacher       *Teacher             `json:"teacher,omitempty"`
+	Teacher             *Teacher             `json:"teacher,omitempty"`
 	// The classrooms that the subject can be taught in, if it's empty, then any available classroom can be used, otherwise, the subject should be taught in one of the classrooms
-	Classrooms    []*Classroom         `json:"classrooms,omitempty"`
+	Classrooms          []*Classroom         `json:"classrooms,omitempty"`
+	// The classrooms the subject should be taught in when possible, using another one is only penalized,
+	// so the solver can resolve a conflict with a less suitable room, empty means no preference
+	PreferredClassrooms []*Classroom         `json:"preferred_classrooms,omitempty"`
 	// The group that the division is split into for that subject
 	// e.g. english could be split into two groups, one group could be taught in the morning and the other in the afternoon
 	// e.g. electronics could be split into three groups, one group could be taught on Monday, the second on Wednesday, and the third on Friday
 	// e.g. polish is not split into groups, so the group is none, and the subject is taught to the whole division at the same time
-	Group         SubjectsGroupType    `json:"group,omitempty"`
+	Group               SubjectsGroupType    `json:"group,omitempty"`
+	// Whether the subject's blocks should start at roughly the same slot on every day it's taught,
+	// so students can form a routine, the spread of the starting slots is penalized
+	SameTimeOfDay       bool                 `json:"same_time_of_day,omitempty"`
+	// The days that the subject can't be placed on, indexed from 0 (Monday),
+	// e.g. [0, 4] means that the subject can't be taught on Monday and Friday
+	ForbiddenDays       []int                `json:"forbidden_days,omitempty"`
+	// The days that the subject should be placed on when possible, indexed from 0 (Monday), placing it
+	// on another day is only penalized, e.g. [1, 3] for double PE on Tuesday and Thursday, empty means no preference
+	PreferredDays       []int                `json:"preferred_days,omitempty"`
+	// Whether the subject is taught to the whole division and must never share its slot with parallel groups,
+	// e.g. assemblies or godz.wych
+	WholeDivision       bool                 `json:"whole_division,omitempty"`
+	// The number of students taught the subject, e.g. the size of the group, 0 means the whole division
+	Students            uint                 `json:"students,omitempty"`
+	// The maximum number of consecutive hours the subject should be taught in a day, longer
+	// runs are penalized, e.g. 2 spreads a 4 hour day of the subject into two blocks, 0 means no limit
+	MaxConsecutive      uint                 `json:"max_consecutive,omitempty"`
+	// The number of classrooms the subject needs at once, e.g. 2 for a lab split between two rooms,
+	// they're reserved from its preferred classrooms and classrooms, 0 means 1
+	RoomsNeeded         uint                 `json:"rooms_needed,omitempty"`
 }
 
 type Division struct {
-	Name     string    `json:"name,omitempty"`
+	Name           string        `json:"name,omitempty"`
 	// The weight of the division, used to determine how important it is to satisfy the constraints of the division
 	// the higher the weight, the more important it is to satisfy the constraints of the division and the earlier
 	// the division is scheduled in the timetable (that division should be scheduled first, so they start their day early)
-	Weight   uint      `json:"weight,omitempty"`
+	Weight         uint          `json:"weight,omitempty"`
 	// The grouping of the division for each subject, indexed by the subject ID
-	Subjects []Subject `json:"subjects,omitempty"` // The subjects that the division has
+	Subjects       []Subject     `json:"subjects,omitempty"` // The subjects that the division has
+	// The first slot that the division can have lessons in, e.g. 1 means that slot 0 must stay empty
+	// because the division's transport arrives late, 0 means no restriction
+	EarliestStart  uint          `json:"earliest_start,omitempty"`
+	// The number of students in the division, used to check that they fit into the classrooms
+	Students       uint          `json:"students,omitempty"`
+	// The number of slots the division's days may span, counted from the first slot of the day, e.g. 6
+	// means that slots 6 and later must stay empty because the division's transport leaves early, 0 means no limit
+	MaxSlotsPerDay uint          `json:"max_slots_per_day,omitempty"`
+	// Subjects of the division that should be taught right after one another on the same day, e.g. theory
+	// followed by its lab, a lesson of either subject without its partner next to it is penalized
+	SubjectPairs   []SubjectPair `json:"subject_pairs,omitempty"`
+}
+
+// Two global subjects of a division, the lessons of First should be immediately followed by the lessons of Then
+type SubjectPair struct {
+	First GlobalSubject `json:"first"`
+	Then  GlobalSubject `json:"then"`
 }
 
 type InputData struct {
@@ -109,6 +277,16 @@ type InputData struct {
 	Classrooms             []Classroom     `json:"classrooms,omitempty"`
 	Teachers               []Teacher       `json:"teachers,omitempty"`
 	Divisions              []Division      `json:"divisions,omitempty"`
+	// Additional constraints of the teachers, teachers without an entry are unconstrained
+	TeacherConstraints     map[Teacher]TeacherConstraints `json:"teacher_constraints,omitempty"`
+	// Pairs of teachers that must never teach at the same time, e.g. because they co-supervise something elsewhere
+	TeacherConflicts       [][2]Teacher    `json:"teacher_conflicts,omitempty"`
+	// The clock times of every slot of a day, indexed by the slot, it also sets the number of slots in a day,
+	// empty means the default calendar's times and the solver's default number of slots
+	SlotSchedule           []TimeSlot      `json:"slot_schedule,omitempty"`
+	// Properties of the global subjects that apply in every division, e.g. scheduling a difficult
+	// subject in the morning, subjects without an entry have no properties
+	GlobalSubjectInfo      map[GlobalSubject]SubjectInfo `json:"global_subject_info,omitempty"`
 }
 
 var GlobalSubjects = []GlobalSubject{
@@ -117,11 +295,13 @@ var GlobalSubjects = []GlobalSubject{
 	"urz.i.syst.m",
 	"j.niemiecki",
 	"j.polski",
+	"r_matematyka",
 	"historia",
 	"godz.wych",
 	"religia",
 	"wf",
 	"fizyka",
+	"geografia",
 	"WOS",
 	"j.ang",
 	"prac.apk.mob",
@@ -132,8 +312,10 @@ var GlobalSubjects = []GlobalSubject{
 }
 
 var Classrooms = []Classroom{
-	"sg4", "sg3", "sj1", "sj7", "14", "12", "47", "44", "4", "SKat", "7", "sj2", 
-	"sj6", "ckz", "39", "107", "108", "42", "45", "38", "52", "40", "46",
+	{Name: "sg4"}, {Name: "sg3"}, {Name: "sj1"}, {Name: "sj7"}, {Name: "14"}, {Name: "12"},
+	{Name: "47"}, {Name: "44"}, {Name: "4"}, {Name: "SKat"}, {Name: "7"}, {Name: "sj2"},
+	{Name: "sj6"}, {Name: "ckz"}, {Name: "39"}, {Name: "107"}, {Name: "108"}, {Name: "42"},
+	{Name: "45"}, {Name: "38"}, {Name: "52"}, {Name: "40"}, {Name: "46"},
 }
 
 var Teachers = []Teacher{
@@ -228,7 +410,6 @@ var Divisions = []Division{
 				Classrooms:    []*Classroom{&Classrooms[9]}, // SKat
 				Group:         SubjectsGroupNone,
 			},
-			// TODO: Add classroom capacity constraints
 			// wf group 1
 			{
 				GlobalSubject: &GlobalSubjects[9],
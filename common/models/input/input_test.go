@@ -0,0 +1,69 @@
+// common/models/input/input_test.go
+package input
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDivisionRoundTrip(t *testing.T) {
+	for _, div := range ExampleInputData.Divisions {
+		data, err := json.Marshal(div)
+		if err != nil {
+			t.Fatalf("%s: %v", div.Name, err)
+		}
+		var got Division
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: %v", div.Name, err)
+		}
+		if !reflect.DeepEqual(got, div) {
+			t.Errorf("%s: decoded %+v, want %+v", div.Name, got, div)
+		}
+	}
+}
+
+func TestAllocationJSON(t *testing.T) {
+	tests := []struct {
+		alloc Allocation
+		data  string
+	}{
+		{Allocation{}, `[]`},
+		{Allocation{1}, `[1]`},
+		{Allocation{0, 2, 1}, `[0,2,1]`},
+		{Allocation{1, 1, 1, 1, 1}, `[1,1,1,1,1]`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.alloc)
+		if err != nil || string(data) != tt.data {
+			t.Errorf("%v: encoded %s (%v), want %s", tt.alloc, data, err, tt.data)
+		}
+		var got Allocation
+		if err := json.Unmarshal([]byte(tt.data), &got); err != nil || got != tt.alloc {
+			t.Errorf("%s: decoded %v (%v), want %v", tt.data, got, err, tt.alloc)
+		}
+	}
+
+	var got Allocation
+	if err := json.Unmarshal([]byte(`[1,1,1,1,1,1]`), &got); err == nil {
+		t.Error("an allocation of 6 entries isn't rejected")
+	}
+}
+
+func TestClassroomUnmarshal(t *testing.T) {
+	tests := []struct {
+		data string
+		want Classroom
+	}{
+		{`"12"`, Classroom{Name: "12"}},
+		{`{"name": "12", "capacity": 30, "building": "A"}`, Classroom{Name: "12", Capacity: 30, Building: "A"}},
+	}
+	for _, tt := range tests {
+		var got Classroom
+		if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+			t.Errorf("%s: %v", tt.data, err)
+		} else if got != tt.want {
+			t.Errorf("%s: decoded %+v, want %+v", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// common/models/input/load.go
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// LoadFromFile reads the input data from a JSON file, the references of the subjects are
+// checked and linked to the entries of the top level slices, like in the example data.
+func LoadFromFile(path string) (InputData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return InputData{}, fmt.Errorf("error reading input file: %w", err)
+	}
+
+	var in InputData
+	if err := json.Unmarshal(data, &in); err != nil {
+		return InputData{}, fmt.Errorf("error parsing input file %s: %w", path, err)
+	}
+
+	if err := in.link(); err != nil {
+		return InputData{}, fmt.Errorf("invalid input file %s: %w", path, err)
+	}
+	return in, nil
+}
+
+// Decode reads the input data as JSON from r and links the references of the subjects
+// like LoadFromFile, e.g. for input data received over the network.
+func Decode(r io.Reader) (InputData, error) {
+	var in InputData
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return InputData{}, fmt.Errorf("error parsing input data: %w", err)
+	}
+
+	if err := in.link(); err != nil {
+		return InputData{}, fmt.Errorf("invalid input data: %w", err)
+	}
+	return in, nil
+}
+
+// link points the references of every subject at the matching entries of the top level
+// slices and sets the group of subjects without one to SubjectsGroupNone, it returns an
+// error naming the division and subject of every dangling reference.
+func (in *InputData) link() error {
+	var errs []error
+
+	for dIdx := range in.Divisions {
+		div := &in.Divisions[dIdx]
+		for sIdx := range div.Subjects {
+			subj := &div.Subjects[sIdx]
+			where := fmt.Sprintf("division %q, subject %d", div.Name, sIdx)
+			if subj.GlobalSubject != nil {
+				where += fmt.Sprintf(" (%s)", *subj.GlobalSubject)
+			}
+
+			if subj.Group == "" {
+				subj.Group = SubjectsGroupNone
+			}
+
+			if subj.GlobalSubject != nil {
+				if idx := slices.Index(in.GlobalSubjects, *subj.GlobalSubject); idx >= 0 {
+					subj.GlobalSubject = &in.GlobalSubjects[idx]
+				} else {
+					errs = append(errs, fmt.Errorf("%s: global subject %q is not listed in global_subjects", where, *subj.GlobalSubject))
+				}
+			}
+
+			if subj.Teacher != nil {
+				if idx := slices.Index(in.Teachers, *subj.Teacher); idx >= 0 {
+					subj.Teacher = &in.Teachers[idx]
+				} else {
+					errs = append(errs, fmt.Errorf("%s: teacher %q is not listed in teachers", where, *subj.Teacher))
+				}
+			}
+
+			for _, rooms := range [][]*Classroom{subj.Classrooms, subj.PreferredClassrooms} {
+				for cIdx, classroom := range rooms {
+					if classroom == nil {
+						errs = append(errs, fmt.Errorf("%s: classroom %d is null", where, cIdx))
+						continue
+					}
+					idx := slices.IndexFunc(in.Classrooms, func(c Classroom) bool { return c.Name == classroom.Name })
+					if idx < 0 {
+						errs = append(errs, fmt.Errorf("%s: classroom %q is not listed in classrooms", where, classroom.Name))
+						continue
+					}
+					rooms[cIdx] = &in.Classrooms[idx]
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
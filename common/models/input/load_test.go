@@ -0,0 +1,67 @@
+// common/models/input/load_test.go
+package input
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeInput writes the input data as JSON to a file in a temporary directory and returns its path.
+func writeInput(t *testing.T, in InputData) string {
+	t.Helper()
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFromFile(t *testing.T) {
+	in, err := LoadFromFile(writeInput(t, ExampleInputData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, ExampleInputData) {
+		t.Error("loaded input data differs from the example data it was written from")
+	}
+
+	// Linked references point into the top level slices
+	subj := in.Divisions[0].Subjects[0]
+	if subj.Teacher != nil && !pointsInto(in.Teachers, subj.Teacher) {
+		t.Errorf("teacher %s of the first subject isn't linked to the teachers", *subj.Teacher)
+	}
+}
+
+func TestLoadFromFileDangling(t *testing.T) {
+	in := exampleData()
+	missing := Teacher("XX")
+	in.Divisions[0].Subjects[0].Teacher = &missing
+
+	_, err := LoadFromFile(writeInput(t, in))
+	if err == nil {
+		t.Fatal("dangling teacher isn't reported")
+	}
+	for _, want := range []string{in.Divisions[0].Name, "subject 0", `teacher "XX"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %s", err, want)
+		}
+	}
+}
+
+// pointsInto reports whether p points at an element of s.
+func pointsInto[T any](s []T, p *T) bool {
+	for i := range s {
+		if &s[i] == p {
+			return true
+		}
+	}
+	return false
+}
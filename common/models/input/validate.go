@@ -0,0 +1,367 @@
+// common/models/input/validate.go
+package input
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// The number of hours a day can hold, like the solver's default day, a chunk of an
+// allocation longer than that can't be placed in a single day
+const MaxDailyHours = 8
+
+// The number of days of a week, like the solver's default week, Validate checks the loads of
+// teachers and divisions against a week of that many days
+const DefaultDaysPerWeek = 5
+
+// ValidationError describes a problem with the input data found at the given path.
+type ValidationError struct {
+	Path    string // e.g. divisions[1].subjects[6].teacher
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+func subjectPath(dIdx, sIdx int, field string) string {
+	return fmt.Sprintf("divisions[%d].subjects[%d].%s", dIdx, sIdx, field)
+}
+
+// OrphanedReferences reports subjects whose GlobalSubject, Teacher or classrooms don't resolve
+// by value to an entry of the top level slices, which usually happens when an entry is removed
+// from a top level slice but a subject still points at it (or at the element that took its place).
+func OrphanedReferences(in InputData) []ValidationError {
+	var errs []ValidationError
+
+	for dIdx, div := range in.Divisions {
+		for sIdx, subj := range div.Subjects {
+			if subj.GlobalSubject != nil && !slices.Contains(in.GlobalSubjects, *subj.GlobalSubject) {
+				errs = append(errs, ValidationError{
+					Path:    subjectPath(dIdx, sIdx, "global_subject"),
+					Message: fmt.Sprintf("global subject %q is not listed in global_subjects", *subj.GlobalSubject),
+				})
+			}
+			if subj.Teacher != nil && !slices.Contains(in.Teachers, *subj.Teacher) {
+				errs = append(errs, ValidationError{
+					Path:    subjectPath(dIdx, sIdx, "teacher"),
+					Message: fmt.Sprintf("teacher %q is not listed in teachers", *subj.Teacher),
+				})
+			}
+			for _, rooms := range []struct {
+				field      string
+				classrooms []*Classroom
+			}{
+				{"classrooms", subj.Classrooms},
+				{"preferred_classrooms", subj.PreferredClassrooms},
+			} {
+				for cIdx, classroom := range rooms.classrooms {
+					path := subjectPath(dIdx, sIdx, fmt.Sprintf("%s[%d]", rooms.field, cIdx))
+					if classroom == nil {
+						errs = append(errs, ValidationError{Path: path, Message: "classroom is nil"})
+					} else if !slices.ContainsFunc(in.Classrooms, func(c Classroom) bool { return c.Name == classroom.Name }) {
+						errs = append(errs, ValidationError{
+							Path:    path,
+							Message: fmt.Sprintf("classroom %q is not listed in classrooms", *classroom),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// Validate checks the input data in one pass and returns every problem found, so they can all
+// be fixed at once, references resolve, divisions have slots between their earliest start and
+// their last slot and pair their own subjects, allocations aren't all zero and fit into days, placements
+// and groups are valid, split subjects don't skip a group, subjects list enough classrooms for the
+// rooms they need at once, teachers are qualified for the subjects they teach and prefer divisions
+// that exist, no teacher or division is allocated more than a week of DefaultDaysPerWeek days holds, subject info belongs
+// to listed global subjects and the slots of the schedule are ordered.
+func (in InputData) Validate() []ValidationError {
+	errs := OrphanedReferences(in)
+	errs = append(errs, in.loadErrors()...)
+
+	for _, teacher := range slices.Sorted(maps.Keys(in.TeacherConstraints)) {
+		for i, blocked := range in.TeacherConstraints[teacher].Unavailable {
+			if blocked.Day < 0 || blocked.Slot < 0 {
+				errs = append(errs, ValidationError{
+					Path:    fmt.Sprintf("teacher_constraints[%q].unavailable[%d]", teacher, i),
+					Message: fmt.Sprintf("day %d, slot %d is not a valid slot", blocked.Day, blocked.Slot),
+				})
+			}
+		}
+		for i, name := range in.TeacherConstraints[teacher].PreferredDivisions {
+			if !slices.ContainsFunc(in.Divisions, func(d Division) bool { return d.Name == name }) {
+				errs = append(errs, ValidationError{
+					Path:    fmt.Sprintf("teacher_constraints[%q].preferred_divisions[%d]", teacher, i),
+					Message: fmt.Sprintf("division %q is not listed in divisions", name),
+				})
+			}
+		}
+	}
+
+	for _, subject := range slices.Sorted(maps.Keys(in.GlobalSubjectInfo)) {
+		if !slices.Contains(in.GlobalSubjects, subject) {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("global_subject_info[%q]", subject),
+				Message: fmt.Sprintf("global subject %q is not listed in global_subjects", subject),
+			})
+		}
+	}
+
+	for i, slot := range in.SlotSchedule {
+		if slot.End <= slot.Start {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("slot_schedule[%d]", i),
+				Message: fmt.Sprintf("slot ends at %s, not after its start at %s", slot.End, slot.Start),
+			})
+		} else if i > 0 && slot.Start < in.SlotSchedule[i-1].End {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("slot_schedule[%d]", i),
+				Message: fmt.Sprintf("slot starts at %s, before the previous slot ends at %s", slot.Start, in.SlotSchedule[i-1].End),
+			})
+		}
+	}
+
+	for dIdx, div := range in.Divisions {
+		if div.MaxSlotsPerDay > 0 && div.MaxSlotsPerDay <= div.EarliestStart {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("divisions[%d].max_slots_per_day", dIdx),
+				Message: fmt.Sprintf("no slot is left between the earliest start %d and the last slot %d", div.EarliestStart, div.MaxSlotsPerDay),
+			})
+		}
+		for i, pair := range div.SubjectPairs {
+			path := fmt.Sprintf("divisions[%d].subject_pairs[%d]", dIdx, i)
+			if pair.First == pair.Then {
+				errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("%q is paired with itself", pair.First)})
+			}
+			for _, subject := range slices.Compact([]GlobalSubject{pair.First, pair.Then}) {
+				if !slices.ContainsFunc(div.Subjects, func(subj Subject) bool {
+					return subj.GlobalSubject != nil && *subj.GlobalSubject == subject
+				}) {
+					errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf("%q is not a subject of the division", subject)})
+				}
+			}
+		}
+		errs = append(errs, groupErrors(div, dIdx)...)
+		for sIdx, subj := range div.Subjects {
+			if subj.Allocation == [5]uint{} {
+				errs = append(errs, ValidationError{
+					Path:    subjectPath(dIdx, sIdx, "allocation"),
+					Message: "allocation is all zero, the subject would never be scheduled",
+				})
+			}
+			for i, hours := range subj.Allocation {
+				if hours > MaxDailyHours {
+					errs = append(errs, ValidationError{
+						Path:    subjectPath(dIdx, sIdx, fmt.Sprintf("allocation[%d]", i)),
+						Message: fmt.Sprintf("%d consecutive hours don't fit into a day of %d", hours, MaxDailyHours),
+					})
+				}
+			}
+			if subj.Placement != "" {
+				if _, err := ParsePlacement(string(subj.Placement)); err != nil {
+					errs = append(errs, ValidationError{Path: subjectPath(dIdx, sIdx, "placement"), Message: err.Error()})
+				}
+			}
+			if subj.Group != "" {
+				if _, err := ParseGroup(string(subj.Group)); err != nil {
+					errs = append(errs, ValidationError{Path: subjectPath(dIdx, sIdx, "group"), Message: err.Error()})
+				}
+			}
+			if subj.RoomsNeeded > 1 {
+				var rooms []string
+				for _, c := range append(slices.Clone(subj.PreferredClassrooms), subj.Classrooms...) {
+					if c != nil && !slices.Contains(rooms, c.Name) {
+						rooms = append(rooms, c.Name)
+					}
+				}
+				if len(rooms) < int(subj.RoomsNeeded) {
+					errs = append(errs, ValidationError{
+						Path:    subjectPath(dIdx, sIdx, "rooms_needed"),
+						Message: fmt.Sprintf("subject needs %d classrooms at once, but lists only %d", subj.RoomsNeeded, len(rooms)),
+					})
+				}
+			}
+			if subj.Teacher != nil && subj.GlobalSubject != nil {
+				c := in.TeacherConstraints[*subj.Teacher]
+				if len(c.Qualifications) > 0 && !slices.Contains(c.Qualifications, *subj.GlobalSubject) {
+					errs = append(errs, ValidationError{
+						Path:    subjectPath(dIdx, sIdx, "teacher"),
+						Message: fmt.Sprintf("teacher %q is not qualified to teach %q", *subj.Teacher, *subj.GlobalSubject),
+					})
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// groupErrors reports the subjects of a division split into groups that skip a group, a subject
+// taught to group three of a division needs subjects of the same global subject for groups one and
+// two, so the groups add up to the whole division.
+func groupErrors(div Division, dIdx int) []ValidationError {
+	groups := make(map[GlobalSubject][]SubjectsGroupType)
+	for _, subj := range div.Subjects {
+		if subj.GlobalSubject != nil {
+			groups[*subj.GlobalSubject] = append(groups[*subj.GlobalSubject], subj.Group)
+		}
+	}
+
+	var errs []ValidationError
+	for sIdx, subj := range div.Subjects {
+		level := slices.Index(AllGroups(), subj.Group)
+		if subj.GlobalSubject == nil || level < 2 {
+			continue
+		}
+		var missing []string
+		for _, group := range AllGroups()[1:level] {
+			if !slices.Contains(groups[*subj.GlobalSubject], group) {
+				missing = append(missing, fmt.Sprintf("%q", group))
+			}
+		}
+		if len(missing) > 0 {
+			errs = append(errs, ValidationError{
+				Path: subjectPath(dIdx, sIdx, "group"),
+				Message: fmt.Sprintf("group %q of %q skips the groups %s of the division",
+					subj.Group, *subj.GlobalSubject, strings.Join(missing, ", ")),
+			})
+		}
+	}
+	return errs
+}
+
+// SlotsPerDay returns the number of slots of a day, the length of the slot schedule, or
+// MaxDailyHours without one.
+func (in InputData) SlotsPerDay() int {
+	if len(in.SlotSchedule) > 0 {
+		return len(in.SlotSchedule)
+	}
+	return MaxDailyHours
+}
+
+// The hours allocated to the teachers and divisions of the input data, see InputData.Loads
+type Loads struct {
+	// The teachers that are allocated hours, in the order they're first found
+	Teachers []Teacher
+	// The hours of every teacher in every division, indexed by the division index
+	TeacherHours map[Teacher][]uint
+	// The hours of every division, indexed by the division index, groups of a division are taught
+	// in parallel, so only the busiest group adds to its load
+	DivisionHours []uint
+}
+
+// Teacher returns the hours of the teacher across all divisions.
+func (l Loads) Teacher(teacher Teacher) uint {
+	total := uint(0)
+	for _, hours := range l.TeacherHours[teacher] {
+		total += hours
+	}
+	return total
+}
+
+// Loads sums the allocated hours of every teacher and division.
+func (in InputData) Loads() Loads {
+	l := Loads{
+		TeacherHours:  make(map[Teacher][]uint),
+		DivisionHours: make([]uint, len(in.Divisions)),
+	}
+	for dIdx, div := range in.Divisions {
+		groupLoads := make(map[SubjectsGroupType]uint)
+		for _, subj := range div.Subjects {
+			hours := uint(0)
+			for _, h := range subj.Allocation {
+				hours += h
+			}
+			groupLoads[subj.Group] += hours
+
+			if subj.Teacher != nil && hours > 0 {
+				if _, ok := l.TeacherHours[*subj.Teacher]; !ok {
+					l.Teachers = append(l.Teachers, *subj.Teacher)
+					l.TeacherHours[*subj.Teacher] = make([]uint, len(in.Divisions))
+				}
+				l.TeacherHours[*subj.Teacher][dIdx] += hours
+			}
+		}
+
+		l.DivisionHours[dIdx] = groupLoads[SubjectsGroupNone] + groupLoads[""] +
+			max(groupLoads[SubjectsGroupOne], groupLoads[SubjectsGroupTwo], groupLoads[SubjectsGroupThree], groupLoads[SubjectsGroupFour])
+	}
+	return l
+}
+
+// TeacherOverloads reports the teachers allocated more hours across divisions than a week of
+// weekSlots slots holds, with their hours in every division, such inputs can't be scheduled
+// without teacher overlaps.
+func (in InputData) TeacherOverloads(weekSlots int) []ValidationError {
+	var errs []ValidationError
+	l := in.Loads()
+	for _, teacher := range l.Teachers {
+		load := l.Teacher(teacher)
+		if int(load) <= weekSlots {
+			continue
+		}
+
+		var byDivision []string
+		for dIdx, hours := range l.TeacherHours[teacher] {
+			if hours > 0 {
+				byDivision = append(byDivision, fmt.Sprintf("%s: %d", in.Divisions[dIdx].Name, hours))
+			}
+		}
+		path := "teachers"
+		if tIdx := slices.Index(in.Teachers, teacher); tIdx >= 0 {
+			path = fmt.Sprintf("teachers[%d]", tIdx)
+		}
+		errs = append(errs, ValidationError{
+			Path: path,
+			Message: fmt.Sprintf("teacher %q is allocated %d hours across divisions (%s), but a week has only %d slots",
+				teacher, load, strings.Join(byDivision, ", "), weekSlots),
+		})
+	}
+	return errs
+}
+
+// DivisionOverloads reports the divisions allocated more hours than a week of the given days holds,
+// a division's days only span the slots of a day between its earliest start and its last slot.
+func (in InputData) DivisionOverloads(days, slotsPerDay int) []ValidationError {
+	var errs []ValidationError
+	l := in.Loads()
+	for dIdx, div := range in.Divisions {
+		lastSlot := slotsPerDay
+		if div.MaxSlotsPerDay > 0 {
+			lastSlot = min(lastSlot, int(div.MaxSlotsPerDay))
+		}
+		weekSlots := days * max(lastSlot-int(div.EarliestStart), 0)
+		if load := l.DivisionHours[dIdx]; int(load) > weekSlots {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("divisions[%d]", dIdx),
+				Message: fmt.Sprintf("division %q is allocated %d hours, but its week has only %d slots", div.Name, load, weekSlots),
+			})
+		}
+	}
+	return errs
+}
+
+// loadErrors reports the teachers and divisions allocated more hours than a week of DefaultDaysPerWeek
+// days holds and the teachers allocated more than their MaxHoursPerWeek.
+func (in InputData) loadErrors() []ValidationError {
+	errs := in.TeacherOverloads(DefaultDaysPerWeek * in.SlotsPerDay())
+	errs = append(errs, in.DivisionOverloads(DefaultDaysPerWeek, in.SlotsPerDay())...)
+
+	l := in.Loads()
+	for _, teacher := range l.Teachers {
+		if limit := in.TeacherConstraints[teacher].MaxHoursPerWeek; limit > 0 && l.Teacher(teacher) > limit {
+			errs = append(errs, ValidationError{
+				Path:    fmt.Sprintf("teacher_constraints[%q].max_hours_per_week", teacher),
+				Message: fmt.Sprintf("teacher %q is allocated %d hours across divisions, above their limit of %d", teacher, l.Teacher(teacher), limit),
+			})
+		}
+	}
+	return errs
+}
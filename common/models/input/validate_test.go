@@ -0,0 +1,168 @@
+// common/models/input/validate_test.go
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// exampleData returns a copy of the example data whose divisions and subjects can be edited
+// without affecting ExampleInputData.
+func exampleData() InputData {
+	in := ExampleInputData
+	in.Divisions = slices.Clone(in.Divisions)
+	for dIdx := range in.Divisions {
+		in.Divisions[dIdx].Subjects = slices.Clone(in.Divisions[dIdx].Subjects)
+	}
+	return in
+}
+
+// hasError reports whether an error was found at the path with a message containing text.
+func hasError(errs []ValidationError, path, text string) bool {
+	return slices.ContainsFunc(errs, func(e ValidationError) bool {
+		return e.Path == path && strings.Contains(e.Message, text)
+	})
+}
+
+func TestOrphanedReferences(t *testing.T) {
+	if errs := OrphanedReferences(ExampleInputData); len(errs) > 0 {
+		t.Fatalf("example data has orphaned references: %v", errs)
+	}
+
+	// The teacher and the classroom of the first subject are removed from the top level slices
+	in := exampleData()
+	subj := in.Divisions[0].Subjects[0]
+	in.Teachers = slices.DeleteFunc(slices.Clone(in.Teachers), func(t Teacher) bool { return t == *subj.Teacher })
+	in.Classrooms = slices.DeleteFunc(slices.Clone(in.Classrooms), func(c Classroom) bool { return c.Name == subj.Classrooms[0].Name })
+
+	errs := OrphanedReferences(in)
+	if !hasError(errs, "divisions[0].subjects[0].teacher", string(*subj.Teacher)) {
+		t.Errorf("dangling teacher %q isn't reported: %v", *subj.Teacher, errs)
+	}
+	if !hasError(errs, "divisions[0].subjects[0].classrooms[0]", subj.Classrooms[0].Name) {
+		t.Errorf("dangling classroom %q isn't reported: %v", subj.Classrooms[0].Name, errs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if errs := ExampleInputData.Validate(); len(errs) != 0 {
+		t.Fatalf("example data is invalid: %v", errs)
+	}
+
+	in := exampleData()
+	subj := &in.Divisions[0].Subjects[0]
+	in.TeacherConstraints = map[Teacher]TeacherConstraints{*subj.Teacher: {Qualifications: []GlobalSubject{"nothing"}}}
+	subj.Classrooms = append(slices.Clone(subj.Classrooms), &Classroom{Name: "missing"})
+	in.Divisions[0].Subjects[1].Allocation = Allocation{}
+	in.Divisions[1].Subjects[0].Allocation = Allocation{MaxDailyHours + 1}
+
+	errs := in.Validate()
+	for _, want := range []struct{ path, text string }{
+		{"divisions[0].subjects[0].teacher", "is not qualified"},
+		{fmt.Sprintf("divisions[0].subjects[0].classrooms[%d]", len(subj.Classrooms)-1), `"missing"`},
+		{"divisions[0].subjects[1].allocation", "all zero"},
+		{"divisions[1].subjects[0].allocation[0]", "don't fit into a day"},
+	} {
+		if !hasError(errs, want.path, want.text) {
+			t.Errorf("no error at %s containing %q in %v", want.path, want.text, errs)
+		}
+	}
+}
+
+func TestValidateEnums(t *testing.T) {
+	// Unknown values decode from user supplied JSON as they are, Validate is what catches them
+	var subj Subject
+	if err := json.Unmarshal([]byte(`{"placement": "sideways", "group": "seven"}`), &subj); err != nil {
+		t.Fatalf("decoding the subject: %v", err)
+	}
+	in := exampleData()
+	in.Divisions[0].Subjects[0].Placement = subj.Placement
+	in.Divisions[1].Subjects[0].Group = subj.Group
+
+	errs := in.Validate()
+	if !hasError(errs, "divisions[0].subjects[0].placement", `unknown placement "sideways"`) {
+		t.Errorf("unknown placement isn't reported: %v", errs)
+	}
+	if !hasError(errs, "divisions[1].subjects[0].group", `unknown group "seven"`) {
+		t.Errorf("out of range group isn't reported: %v", errs)
+	}
+}
+
+func TestDanglingGroup(t *testing.T) {
+	physics := GlobalSubject("fizyka")
+	lj := Teacher("LJ")
+	in := InputData{GlobalSubjects: []GlobalSubject{physics}, Teachers: []Teacher{lj}, Divisions: []Division{{Name: "1A", Subjects: []Subject{
+		{GlobalSubject: &physics, Teacher: &lj, Group: SubjectsGroupThree, Allocation: Allocation{1}},
+	}}}}
+
+	errs := in.Validate()
+	if !hasError(errs, "divisions[0].subjects[0].group", `skips the groups "one", "two"`) {
+		t.Errorf("dangling group three isn't reported: %v", errs)
+	}
+
+	// With groups one and two the third one adds up
+	in.Divisions[0].Subjects = append(in.Divisions[0].Subjects,
+		Subject{GlobalSubject: &physics, Teacher: &lj, Group: SubjectsGroupOne, Allocation: Allocation{1}},
+		Subject{GlobalSubject: &physics, Teacher: &lj, Group: SubjectsGroupTwo, Allocation: Allocation{1}},
+	)
+	if errs := in.Validate(); hasError(errs, "divisions[0].subjects[0].group", "skips") {
+		t.Errorf("group three with groups one and two is reported: %v", errs)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	// A teacher of every subject of two divisions is allocated both their weeks
+	in := exampleData()
+	lj := in.Teachers[0]
+	for dIdx := range in.Divisions[:2] {
+		for sIdx := range in.Divisions[dIdx].Subjects {
+			in.Divisions[dIdx].Subjects[sIdx].Teacher = &lj
+		}
+	}
+	loads := in.Loads()
+	total := loads.Teacher(lj)
+	if want := loads.DivisionHours[0] + loads.DivisionHours[1]; total < want {
+		t.Fatalf("teacher load = %d, want at least the %d hours of both divisions", total, want)
+	}
+
+	week := int(total) - 1
+	if errs := in.TeacherOverloads(week); !hasError(errs, "teachers[0]", fmt.Sprintf("allocated %d hours", total)) {
+		t.Errorf("teacher allocated %d hours isn't reported against a week of %d: %v", total, week, errs)
+	}
+	if errs := in.TeacherOverloads(int(total)); len(errs) != 0 {
+		t.Errorf("teacher fitting into the week is reported: %v", errs)
+	}
+
+	// A shorter day of the slot schedule shrinks the week Validate checks against
+	in.SlotSchedule = make([]TimeSlot, 1)
+	if errs := in.DivisionOverloads(DefaultDaysPerWeek, in.SlotsPerDay()); !hasError(errs, "divisions[0]", "only 5 slots") {
+		t.Errorf("division overflowing a week of one slot days isn't reported: %v", errs)
+	}
+}
+
+func TestNilClassroom(t *testing.T) {
+	in := exampleData()
+	subj := &in.Divisions[0].Subjects[0]
+	subj.Classrooms = append(slices.Clone(subj.Classrooms), nil)
+
+	path := fmt.Sprintf("divisions[0].subjects[0].classrooms[%d]", len(subj.Classrooms)-1)
+	if errs := in.Validate(); !hasError(errs, path, "nil") {
+		t.Errorf("nil classroom isn't reported at %s: %v", path, errs)
+	}
+}
+
+func TestDivisionBounds(t *testing.T) {
+	in := exampleData()
+	in.Divisions[0].EarliestStart, in.Divisions[0].MaxSlotsPerDay = 6, 6
+
+	// Slots past the end of the day aren't a dangling reference
+	if errs := OrphanedReferences(in); len(errs) > 0 {
+		t.Errorf("orphaned references = %v, want none", errs)
+	}
+	if errs := in.Validate(); !hasError(errs, "divisions[0].max_slots_per_day", "no slot is left") {
+		t.Errorf("a division without slots isn't reported: %v", errs)
+	}
+}
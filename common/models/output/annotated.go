@@ -0,0 +1,23 @@
+// common/models/output/annotated.go
+package output
+
+// Annotation explains why a subject is placed in a slot
+type Annotation struct {
+	Satisfied []string `json:"satisfied,omitempty"` // Constraints satisfied by the placement
+	Penalties []string `json:"penalties,omitempty"` // Violations and soft penalties incurred by the placement
+}
+
+type AnnotatedSubject struct {
+	Subject
+	Annotation Annotation `json:"annotation"`
+}
+
+type AnnotatedSubjectsGroup []AnnotatedSubject  // The non-empty subjects of a SubjectsGroup
+type AnnotatedDay           []AnnotatedSubjectsGroup
+type AnnotatedDays          []AnnotatedDay
+
+// An opt-in enriched version of OutputData, with every placed subject accompanied by an annotation
+type AnnotatedOutputData struct {
+	DivisionsTimetables []AnnotatedDays `json:"timetables,omitempty"`
+	Fitness             int             `json:"fitness"`
+}
@@ -0,0 +1,85 @@
+// common/models/output/calendar.go
+package output
+
+import (
+	"strconv"
+	"time"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+// Calendar maps the day and slot indices of a timetable to day names and clock times
+type Calendar struct {
+	DayNames      []string      // Names of the days counted from Monday, DayName is used for the missing ones
+	DayStart      time.Duration // The start of the first slot, counted from midnight
+	SlotDuration  time.Duration
+	BreakDuration time.Duration // The break between two consecutive slots
+	// The clock times of the slots, they override the durations above, slots past
+	// the last one follow it with SlotDuration and BreakDuration
+	Slots []input.TimeSlot
+}
+
+var DefaultCalendar = Calendar{
+	DayNames:      DayNames,
+	DayStart:      8 * time.Hour,
+	SlotDuration:  45 * time.Minute,
+	BreakDuration: 10 * time.Minute,
+}
+
+// DayName returns the calendar's name of the day, counted from 0 (Monday).
+func (c Calendar) DayName(day int) string {
+	if day >= 0 && day < len(c.DayNames) {
+		return c.DayNames[day]
+	}
+	return DayName(day)
+}
+
+// dayNames returns the names of the first days days of the week.
+func (c Calendar) dayNames(days int) []string {
+	names := make([]string, days)
+	for day := range names {
+		names[day] = c.DayName(day)
+	}
+	return names
+}
+
+// ForInput returns the calendar with the slot schedule of the input data, unless it already has slots.
+func (c Calendar) ForInput(in input.InputData) Calendar {
+	if len(c.Slots) == 0 {
+		c.Slots = in.SlotSchedule
+	}
+	return c
+}
+
+// SlotStart returns the start of the slot, counted from midnight.
+func (c Calendar) SlotStart(slot int) time.Duration {
+	if n := len(c.Slots); n > 0 {
+		if slot < n {
+			return time.Duration(c.Slots[slot].Start)
+		}
+		return time.Duration(c.Slots[n-1].End) + c.BreakDuration + time.Duration(slot-n)*(c.SlotDuration+c.BreakDuration)
+	}
+	return c.DayStart + time.Duration(slot)*(c.SlotDuration+c.BreakDuration)
+}
+
+// SlotEnd returns the end of the slot, counted from midnight.
+func (c Calendar) SlotEnd(slot int) time.Duration {
+	if slot < len(c.Slots) {
+		return time.Duration(c.Slots[slot].End)
+	}
+	return c.SlotStart(slot) + c.SlotDuration
+}
+
+// SlotLabel returns the clock times of the slot, e.g. "08:00-08:45".
+func (c Calendar) SlotLabel(slot int) string {
+	return input.ClockTime(c.SlotStart(slot)).String() + "-" + input.ClockTime(c.SlotEnd(slot)).String()
+}
+
+// slotRowLabel returns the label of a slot's row in the exported grids, its number,
+// followed by its clock times when the input data has a slot schedule.
+func slotRowLabel(in input.InputData, slot int) string {
+	if len(in.SlotSchedule) == 0 {
+		return strconv.Itoa(slot + 1)
+	}
+	return strconv.Itoa(slot+1) + " (" + DefaultCalendar.ForInput(in).SlotLabel(slot) + ")"
+}
@@ -0,0 +1,60 @@
+// common/models/output/calendar_test.go
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestSlotSchedule(t *testing.T) {
+	data, in := sampleData()
+	clock := func(h, m int) input.ClockTime {
+		return input.ClockTime(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+	}
+	// A 90 minute block in the second slot, nothing like the default 45 minute slots from 8:00
+	in.SlotSchedule = []input.TimeSlot{
+		{Start: clock(7, 30), End: clock(8, 15)},
+		{Start: clock(8, 20), End: clock(9, 50)},
+		{Start: clock(10, 0), End: clock(10, 45)},
+		{Start: clock(10, 50), End: clock(11, 35)},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, data, in); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("written CSV doesn't parse: %v", err)
+	}
+	if got, want := records[2][0], "2 (08:20-09:50)"; got != want {
+		t.Errorf("CSV slot 2 label = %q, want %q", got, want)
+	}
+
+	monday := time.Date(2026, time.September, 7, 0, 0, 0, 0, time.UTC)
+	buf.Reset()
+	if err := WriteICS(&buf, data, in, 0, monday); err != nil {
+		t.Fatal(err)
+	}
+	var starts, ends []string
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if value, ok := strings.CutPrefix(line, "DTSTART:"); ok {
+			starts = append(starts, value)
+		}
+		if value, ok := strings.CutPrefix(line, "DTEND:"); ok {
+			ends = append(ends, value)
+		}
+	}
+	// Monday's slots 1, 2 and 4 and Tuesday's first slot
+	wantStarts := []string{"20260907T073000Z", "20260907T082000Z", "20260907T105000Z", "20260908T073000Z"}
+	wantEnds := []string{"20260907T081500Z", "20260907T095000Z", "20260907T113500Z", "20260908T081500Z"}
+	if !slices.Equal(starts, wantStarts) || !slices.Equal(ends, wantEnds) {
+		t.Errorf("ICS events run %v to %v, want %v to %v", starts, ends, wantStarts, wantEnds)
+	}
+}
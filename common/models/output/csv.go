@@ -0,0 +1,52 @@
+// common/models/output/csv.go
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+// The separator of lessons taught at the same time within a CSV cell
+const csvGroupSeparator = " / "
+
+// WriteCSV writes a grid per division with the days as columns and the slots as rows,
+// each grid starts with a header row holding the division name and the day names,
+// grids are separated by an empty row, slots are labeled with their times when the
+// input data has a slot schedule.
+func WriteCSV(w io.Writer, data OutputData, in input.InputData) error {
+	cw := csv.NewWriter(w)
+	weekDays := data.WeekLength()
+
+	for dIdx, days := range data.DivisionsTimetables {
+		if dIdx > 0 {
+			if err := cw.Write([]string{""}); err != nil {
+				return err
+			}
+		}
+
+		header := append([]string{divisionName(in, dIdx)}, DefaultCalendar.dayNames(weekDays)...)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+
+		slots := 0
+		for _, day := range days {
+			slots = max(slots, len(day))
+		}
+		for slot := 0; slot < slots; slot++ {
+			record := []string{slotRowLabel(in, slot)}
+			for day := 0; day < weekDays; day++ {
+				record = append(record, strings.Join(lessonLabels(days.At(day), slot), csvGroupSeparator))
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
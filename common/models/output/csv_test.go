@@ -0,0 +1,32 @@
+// common/models/output/csv_test.go
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	data, in := sampleData()
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, data, in); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("written CSV doesn't parse: %v", err)
+	}
+	if want := []string{"1A", "Monday", "Tuesday"}; !slices.Equal(records[0], want) {
+		t.Errorf("header = %q, want %q", records[0], want)
+	}
+	// Monday's second slot has both English groups
+	if got, want := records[2][1], "angielski AK @107 / angielski LJ @12"; got != want {
+		t.Errorf("Monday slot 2 = %q, want %q", got, want)
+	}
+	if len(records) != 5 {
+		t.Errorf("%d rows, want the header and 4 slots", len(records))
+	}
+}
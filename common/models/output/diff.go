@@ -0,0 +1,124 @@
+// common/models/output/diff.go
+package output
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+// The position of a lesson within a division's timetable
+type lessonPosition struct {
+	day  int
+	slot int
+}
+
+func (p lessonPosition) String() string {
+	return fmt.Sprintf("%s slot %d", DefaultCalendar.DayName(p.day), p.slot+1)
+}
+
+// A difference between two timetables of a division, along with the position it's ordered by
+type lessonChange struct {
+	at   lessonPosition
+	text string
+}
+
+// Diff compares the timetables of two solves, e.g. to see the effect of a parameter change, it
+// returns a line for every lesson that was moved, added or removed, ordered by division, day and
+// slot. Lessons are told apart by their subject, teacher, group and classrooms, the order of the
+// parallel groups within a slot doesn't matter, a lesson missing from one slot and found in another
+// is reported as moved. Divisions are named by their index, there's no input data to name them.
+func Diff(a, b OutputData) []string {
+	var lines []string
+	for dIdx := 0; dIdx < max(len(a.DivisionsTimetables), len(b.DivisionsTimetables)); dIdx++ {
+		var daysA, daysB Days
+		if dIdx < len(a.DivisionsTimetables) {
+			daysA = a.DivisionsTimetables[dIdx]
+		}
+		if dIdx < len(b.DivisionsTimetables) {
+			daysB = b.DivisionsTimetables[dIdx]
+		}
+
+		name := divisionName(input.InputData{}, dIdx)
+		for _, c := range diffDays(daysA, daysB) {
+			lines = append(lines, name+": "+c.text)
+		}
+	}
+	return lines
+}
+
+// diffDays returns the lessons moved, added or removed between two timetables of a division,
+// lessons found at the same position in both are left out, the rest are paired up in the order
+// of their positions, so a lesson that appears in a slot and disappears from another is moved.
+func diffDays(a, b Days) []lessonChange {
+	removed, added := lessonPositions(a), lessonPositions(b)
+	for label, from := range removed {
+		to := added[label]
+		for i := 0; i < len(from); {
+			if j := slices.Index(to, from[i]); j >= 0 {
+				from = slices.Delete(from, i, i+1)
+				to = slices.Delete(to, j, j+1)
+				continue
+			}
+			i++
+		}
+		removed[label], added[label] = from, to
+	}
+
+	// The changes are sorted by their positions, so the order of the labels doesn't matter
+	var changes []lessonChange
+	labels := slices.Collect(maps.Keys(removed))
+	for label := range added {
+		if _, ok := removed[label]; !ok {
+			labels = append(labels, label)
+		}
+	}
+	for _, label := range labels {
+		from, to := removed[label], added[label]
+		for i := 0; i < max(len(from), len(to)); i++ {
+			switch {
+			case i < len(from) && i < len(to):
+				changes = append(changes, lessonChange{from[i], fmt.Sprintf("%s moved from %s to %s", label, from[i], to[i])})
+			case i < len(from):
+				changes = append(changes, lessonChange{from[i], fmt.Sprintf("%s removed from %s", label, from[i])})
+			default:
+				changes = append(changes, lessonChange{to[i], fmt.Sprintf("%s added on %s", label, to[i])})
+			}
+		}
+	}
+
+	slices.SortFunc(changes, func(x, y lessonChange) int {
+		return cmp.Or(cmp.Compare(x.at.day, y.at.day), cmp.Compare(x.at.slot, y.at.slot), cmp.Compare(x.text, y.text))
+	})
+	return changes
+}
+
+// lessonPositions returns the positions of the lessons of the days by their labels, see
+// diffLabel, in the order of the days and slots.
+func lessonPositions(days Days) map[string][]lessonPosition {
+	positions := make(map[string][]lessonPosition)
+	for day, d := range days {
+		for slot, sg := range d {
+			for _, subj := range sg {
+				if subj.GlobalSubject != nil {
+					label := diffLabel(subj)
+					positions[label] = append(positions[label], lessonPosition{day, slot})
+				}
+			}
+		}
+	}
+	return positions
+}
+
+// diffLabel returns the label of a lesson with its group, unless it's taught to the whole
+// division, e.g. "angielski AK @12 (group one)".
+func diffLabel(subj Subject) string {
+	label := lessonLabel(subj)
+	if subj.Group != nil && *subj.Group != input.SubjectsGroupNone {
+		label += fmt.Sprintf(" (group %s)", *subj.Group)
+	}
+	return label
+}
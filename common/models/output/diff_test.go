@@ -0,0 +1,34 @@
+// common/models/output/diff_test.go
+package output
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before, _ := sampleData()
+	if lines := Diff(before, before); len(lines) != 0 {
+		t.Errorf("a timetable differs from itself by %q", lines)
+	}
+
+	// The parallel groups of a slot swapped places, no lesson moved
+	swapped := OutputData{DivisionsTimetables: []Days{before.DivisionsTimetables[0].Clone()}}
+	slices.Reverse(swapped.DivisionsTimetables[0][0][1])
+	if lines := Diff(before, swapped); len(lines) != 0 {
+		t.Errorf("reordering parallel groups differs by %q, want nothing", lines)
+	}
+
+	// The math of Monday's last slot moved to the end of Tuesday
+	after := OutputData{DivisionsTimetables: []Days{before.DivisionsTimetables[0].Clone()}}
+	days := after.DivisionsTimetables[0]
+	math := days[0].Slot(3)
+	days[0] = days[0][:3]
+	days[1] = days[1].Place(len(days[1]), math)
+
+	lines := Diff(before, after)
+	want := []string{"Division 0: matematyka LJ @12 moved from Monday slot 4 to Tuesday slot 2"}
+	if !slices.Equal(lines, want) {
+		t.Errorf("moving a lesson differs by %q, want %q", lines, want)
+	}
+}
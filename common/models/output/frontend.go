@@ -0,0 +1,113 @@
+// common/models/output/frontend.go
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+/* Frontend contract
+The frontend consumes a denormalized shape that is decoupled from the solver's internal structs,
+so that refactoring OutputData doesn't break the UI. Names are resolved to plain strings, every day
+of a division has the same number of slots and empty slots are explicit empty lists.
+*/
+
+var DayNames = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// DayName returns the name of the day of the week, counted from 0 (Monday).
+func DayName(day int) string {
+	if day >= 0 && day < len(DayNames) {
+		return DayNames[day]
+	}
+	return fmt.Sprintf("Day %d", day+1)
+}
+
+type FrontendLesson struct {
+	Subject   string `json:"subject"`
+	Teacher   string `json:"teacher"`
+	Classroom string `json:"classroom"`
+	Group     string `json:"group"`
+}
+
+type FrontendDay struct {
+	Name  string             `json:"name"`
+	Slots [][]FrontendLesson `json:"slots"` // Lessons taught at the same time, empty if the slot is free
+}
+
+type FrontendDivision struct {
+	Index int           `json:"index"`
+	Name  string        `json:"name"`
+	Days  []FrontendDay `json:"days"`
+}
+
+type FrontendData struct {
+	Fitness   int                `json:"fitness"`
+	Divisions []FrontendDivision `json:"divisions"`
+}
+
+// Frontend converts the output data into the shape expected by the frontend.
+func Frontend(data OutputData, in input.InputData) FrontendData {
+	result := FrontendData{
+		Fitness:   data.Fitness,
+		Divisions: make([]FrontendDivision, 0, len(data.DivisionsTimetables)),
+	}
+
+	for dIdx, days := range data.DivisionsTimetables {
+		div := FrontendDivision{
+			Index: dIdx,
+			Days:  make([]FrontendDay, 0, len(days)),
+		}
+		if dIdx < len(in.Divisions) {
+			div.Name = in.Divisions[dIdx].Name
+		}
+
+		rows := 0
+		for _, day := range days {
+			rows = max(rows, len(day))
+		}
+
+		for dayIdx, day := range days {
+			fd := FrontendDay{
+				Name:  DayName(dayIdx),
+				Slots: make([][]FrontendLesson, rows),
+			}
+			for slot := range fd.Slots {
+				fd.Slots[slot] = []FrontendLesson{}
+				for _, subj := range day.Slot(slot) {
+					if subj.GlobalSubject == nil {
+						continue
+					}
+					fd.Slots[slot] = append(fd.Slots[slot], frontendLesson(subj))
+				}
+			}
+			div.Days = append(div.Days, fd)
+		}
+
+		result.Divisions = append(result.Divisions, div)
+	}
+
+	return result
+}
+
+// WriteFrontendJSON writes the output data in the shape expected by the frontend.
+func WriteFrontendJSON(w io.Writer, data OutputData, in input.InputData) error {
+	return json.NewEncoder(w).Encode(Frontend(data, in))
+}
+
+func frontendLesson(subj Subject) FrontendLesson {
+	var lesson FrontendLesson
+	if subj.GlobalSubject != nil {
+		lesson.Subject = string(*subj.GlobalSubject)
+	}
+	if subj.Teacher != nil {
+		lesson.Teacher = string(*subj.Teacher)
+	}
+	lesson.Classroom = classroomLabel(subj)
+	if subj.Group != nil {
+		lesson.Group = string(*subj.Group)
+	}
+	return lesson
+}
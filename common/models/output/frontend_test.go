@@ -0,0 +1,72 @@
+// common/models/output/frontend_test.go
+package output
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+// golden compares got with the golden file testdata/name, or rewrites it with -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file:\n%s\nwant:\n%s", name, got, want)
+	}
+}
+
+// sampleData returns a small solved week of a single division, with parallel groups, a
+// free slot and a shorter day.
+func sampleData() (OutputData, input.InputData) {
+	math, english := input.GlobalSubject("matematyka"), input.GlobalSubject("angielski")
+	lj, ak := input.Teacher("LJ"), input.Teacher("AK")
+	r12, r107 := input.Classroom{Name: "12"}, input.Classroom{Name: "107"}
+	one, two := input.SubjectsGroupOne, input.SubjectsGroupTwo
+
+	in := input.InputData{
+		GlobalSubjects: []input.GlobalSubject{math, english},
+		Teachers:       []input.Teacher{lj, ak},
+		Classrooms:     []input.Classroom{r12, r107},
+		Divisions:      []input.Division{{Name: "1A"}},
+	}
+	data := OutputData{
+		DivisionsTimetables: []Days{{
+			{
+				{{GlobalSubject: &math, Teacher: &lj, Classroom: &r12}},
+				{{GlobalSubject: &english, Teacher: &ak, Classroom: &r107, Group: &one}, {GlobalSubject: &english, Teacher: &lj, Classroom: &r12, Group: &two}},
+				{},
+				{{GlobalSubject: &math, Teacher: &lj, Classroom: &r12}},
+			},
+			{
+				{{GlobalSubject: &english, Teacher: &ak, Classroom: &r107}},
+			},
+		}},
+		Fitness: 3,
+	}
+	return data, in
+}
+
+func TestWriteFrontendJSON(t *testing.T) {
+	data, in := sampleData()
+	var buf bytes.Buffer
+	if err := WriteFrontendJSON(&buf, data, in); err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "frontend.golden.json", buf.Bytes())
+}
@@ -0,0 +1,104 @@
+// common/models/output/html.go
+package output
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"io"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+type htmlLesson struct {
+	Label string
+	Color template.CSS
+}
+
+type htmlRow struct {
+	Label string
+	Cells [][]htmlLesson // Lessons taught at the same time in each day, stacked within the cell
+}
+
+type htmlDivision struct {
+	Name string
+	Rows []htmlRow
+}
+
+type htmlPage struct {
+	Days      []string
+	Divisions []htmlDivision
+}
+
+var htmlTemplate = template.Must(template.New("timetables").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Timetables</title>
+<style>
+body { font-family: sans-serif; margin: 20px; }
+table { border-collapse: collapse; margin-bottom: 20px; }
+th, td { border: 1px solid #000; padding: 4px; vertical-align: top; min-width: 120px; }
+th { background: #eee; }
+td div { padding: 2px 4px; margin: 1px 0; white-space: nowrap; }
+</style>
+</head>
+<body>
+{{range .Divisions}}
+<h2>{{.Name}}</h2>
+<table>
+<thead><tr><th></th>{{range $.Days}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr><th>{{.Label}}</th>{{range .Cells}}<td>{{range .}}<div style="background: {{.Color}}">{{.Label}}</div>{{end}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+// RenderHTML writes a page with a table per division, days as columns and slots as rows,
+// lessons are colored by subject and parallel groups are stacked within a cell.
+func RenderHTML(w io.Writer, data OutputData, in input.InputData) error {
+	weekDays := data.WeekLength()
+	page := htmlPage{Days: DefaultCalendar.dayNames(weekDays)}
+
+	for dIdx, days := range data.DivisionsTimetables {
+		div := htmlDivision{Name: divisionName(in, dIdx)}
+
+		slots := 0
+		for _, day := range days {
+			slots = max(slots, len(day))
+		}
+		for slot := 0; slot < slots; slot++ {
+			row := htmlRow{Label: slotRowLabel(in, slot)}
+			for dayIdx := 0; dayIdx < weekDays; dayIdx++ {
+				day := days.At(dayIdx)
+				var cell []htmlLesson
+				for _, subj := range day.Slot(slot) {
+					if subj.GlobalSubject == nil {
+						continue
+					}
+					cell = append(cell, htmlLesson{
+						Label: lessonLabel(subj),
+						Color: subjectColor(string(*subj.GlobalSubject)),
+					})
+				}
+				row.Cells = append(row.Cells, cell)
+			}
+			div.Rows = append(div.Rows, row)
+		}
+
+		page.Divisions = append(page.Divisions, div)
+	}
+
+	return htmlTemplate.Execute(w, page)
+}
+
+// subjectColor returns a light color derived from the subject name, so a subject
+// has the same color in every table.
+func subjectColor(subject string) template.CSS {
+	h := fnv.New32a()
+	h.Write([]byte(subject))
+	return template.CSS(fmt.Sprintf("hsl(%d, 70%%, 85%%)", h.Sum32()%360))
+}
@@ -0,0 +1,39 @@
+// common/models/output/html_test.go
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestRenderHTML(t *testing.T) {
+	data, in := sampleData()
+	// A second division whose names need escaping
+	tricky, teacher := input.GlobalSubject("<b>chemia</b>"), input.Teacher(`O"Brien & co`)
+	in.Divisions = append(in.Divisions, input.Division{Name: "2<B>"})
+	data.DivisionsTimetables = append(data.DivisionsTimetables, Days{{{{GlobalSubject: &tricky, Teacher: &teacher}}}})
+
+	var buf bytes.Buffer
+	if err := RenderHTML(&buf, data, in); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+
+	if n := strings.Count(html, "<table>"); n != 2 {
+		t.Errorf("%d tables, want one per division", n)
+	}
+	if !strings.Contains(html, "matematyka LJ @12") {
+		t.Error("the lessons of the first division are missing")
+	}
+	for _, raw := range []string{"<b>chemia", "2<B>", "& co"} {
+		if strings.Contains(html, raw) {
+			t.Errorf("%q isn't escaped", raw)
+		}
+	}
+	if !strings.Contains(html, "&lt;b&gt;chemia&lt;/b&gt;") {
+		t.Error("the escaped subject of the second division is missing")
+	}
+}
@@ -0,0 +1,102 @@
+// common/models/output/ics.go
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// WriteICS writes the weekly schedule of a single division as an iCalendar file with
+// DefaultCalendar's times, see Calendar.WriteICS.
+func WriteICS(w io.Writer, data OutputData, in input.InputData, divisionIndex int, startDate time.Time) error {
+	return DefaultCalendar.WriteICS(w, data, in, divisionIndex, startDate)
+}
+
+// WriteICS writes the weekly schedule of a single division as an iCalendar file, with an
+// event per slot that has lessons, startDate is the Monday of the week, only its date
+// and location are used, the times of the slots are taken from the calendar, or from the
+// slot schedule of the input data when the calendar has no slots.
+func (c Calendar) WriteICS(w io.Writer, data OutputData, in input.InputData, divisionIndex int, startDate time.Time) error {
+	c = c.ForInput(in)
+	if divisionIndex < 0 || divisionIndex >= len(data.DivisionsTimetables) {
+		return fmt.Errorf("division index %d out of range, there are %d divisions",
+			divisionIndex, len(data.DivisionsTimetables))
+	}
+
+	bw := bufio.NewWriter(w)
+	line := func(format string, args ...any) {
+		writeICSLine(bw, fmt.Sprintf(format, args...))
+	}
+
+	monday := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	stamp := time.Now().UTC().Format(icsTimeFormat)
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Arrango//Timetable//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:%s", icsEscape(divisionName(in, divisionIndex)))
+
+	for day, d := range data.DivisionsTimetables[divisionIndex] {
+		date := monday.AddDate(0, 0, day)
+		for slot, sg := range d {
+			var subjects, classrooms []string
+			for _, subj := range sg {
+				if subj.GlobalSubject == nil {
+					continue
+				}
+				subjects = append(subjects, string(*subj.GlobalSubject))
+				for _, c := range subj.Classrooms() {
+					classrooms = append(classrooms, c.Name)
+				}
+			}
+			if len(subjects) == 0 {
+				continue
+			}
+
+			start := date.Add(c.SlotStart(slot))
+			end := date.Add(c.SlotEnd(slot))
+			line("BEGIN:VEVENT")
+			line("UID:%s-%d-%d-%d@arrango", monday.Format("20060102"), divisionIndex, day, slot)
+			line("DTSTAMP:%s", stamp)
+			line("DTSTART:%s", start.UTC().Format(icsTimeFormat))
+			line("DTEND:%s", end.UTC().Format(icsTimeFormat))
+			line("SUMMARY:%s", icsEscape(strings.Join(subjects, " / ")))
+			if len(classrooms) > 0 {
+				line("LOCATION:%s", icsEscape(strings.Join(classrooms, ", ")))
+			}
+			line("END:VEVENT")
+		}
+	}
+
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icsEscape escapes the characters that have a meaning in iCalendar text values.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// writeICSLine writes a content line, folding it every 75 octets as the format requires,
+// without splitting UTF-8 sequences.
+func writeICSLine(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74 // The leading space of a continuation counts too
+	}
+	w.WriteString(s + "\r\n")
+}
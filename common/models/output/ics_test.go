@@ -0,0 +1,50 @@
+// common/models/output/ics_test.go
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteICS(t *testing.T) {
+	data, in := sampleData()
+	monday := time.Date(2026, time.September, 7, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, data, in, 0, monday); err != nil {
+		t.Fatal(err)
+	}
+
+	// An event per slot with lessons, the parallel groups share theirs
+	ics := buf.String()
+	if n := strings.Count(ics, "BEGIN:VEVENT"); n != 4 {
+		t.Errorf("%d events, want 4", n)
+	}
+
+	var starts []time.Time
+	for _, line := range strings.Split(ics, "\r\n") {
+		if value, ok := strings.CutPrefix(line, "DTSTART:"); ok {
+			start, err := time.Parse(icsTimeFormat, value)
+			if err != nil {
+				t.Fatalf("DTSTART %q doesn't parse: %v", value, err)
+			}
+			starts = append(starts, start)
+		}
+	}
+	if len(starts) == 0 || !starts[0].Equal(monday.Add(8*time.Hour)) {
+		t.Fatalf("starts = %v, want the first lesson on Monday at 8:00", starts)
+	}
+	for i, start := range starts {
+		if start.Before(monday) || !start.Before(monday.AddDate(0, 0, 2)) || start.Hour() < 8 || start.Hour() > 16 {
+			t.Errorf("event %d starts %v, want Monday or Tuesday during school hours", i, start)
+		}
+		if i > 0 && !start.After(starts[i-1]) {
+			t.Errorf("event %d starts %v, not after the previous one at %v", i, start, starts[i-1])
+		}
+	}
+
+	if err := WriteICS(&buf, data, in, 1, monday); err == nil {
+		t.Error("an out of range division isn't rejected")
+	}
+}
@@ -0,0 +1,50 @@
+// common/models/output/ndjson.go
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// A line of the NDJSON output, the timetable of a single division
+type DivisionLine struct {
+	DivisionIndex int  `json:"division_index"`
+	Days          Days `json:"days"`
+}
+
+// WriteNDJSON writes a JSON object per division on its own line, so consumers can parse
+// the output as a stream instead of holding the timetables of every division at once.
+func WriteNDJSON(w io.Writer, data OutputData) error {
+	enc := json.NewEncoder(w)
+	for dIdx, days := range data.DivisionsTimetables {
+		if err := enc.Encode(DivisionLine{DivisionIndex: dIdx, Days: days}); err != nil {
+			return fmt.Errorf("error writing division %d: %w", dIdx, err)
+		}
+	}
+	return nil
+}
+
+// ReadNDJSON reads the lines written by WriteNDJSON back into the timetables of the
+// divisions, placed by their division index regardless of the order of the lines.
+func ReadNDJSON(r io.Reader) ([]Days, error) {
+	var timetables []Days
+	dec := json.NewDecoder(r)
+	for {
+		var line DivisionLine
+		if err := dec.Decode(&line); errors.Is(err, io.EOF) {
+			return timetables, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading division line: %w", err)
+		}
+		if line.DivisionIndex < 0 {
+			return nil, fmt.Errorf("invalid division index %d", line.DivisionIndex)
+		}
+
+		for len(timetables) <= line.DivisionIndex {
+			timetables = append(timetables, nil)
+		}
+		timetables[line.DivisionIndex] = line.Days
+	}
+}
@@ -0,0 +1,41 @@
+// common/models/output/ndjson_test.go
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNDJSON(t *testing.T) {
+	data, _ := sampleData()
+	data.DivisionsTimetables = append(data.DivisionsTimetables, data.DivisionsTimetables[0][1:])
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(data.DivisionsTimetables) {
+		t.Fatalf("%d lines, want one per division %d", len(lines), len(data.DivisionsTimetables))
+	}
+	// Lines are placed by their division index, not by their order
+	reversed := slices.Clone(lines)
+	slices.Reverse(reversed)
+
+	want, _ := json.Marshal(data.DivisionsTimetables)
+	for name, stream := range map[string]string{
+		"in order": buf.String(),
+		"reversed": strings.Join(reversed, "\n"),
+	} {
+		timetables, err := ReadNDJSON(strings.NewReader(stream))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, _ := json.Marshal(timetables); !bytes.Equal(got, want) {
+			t.Errorf("%s: read back %s, want %s", name, got, want)
+		}
+	}
+}
@@ -9,24 +9,126 @@ be taught in the morning and the other in the afternoon, or electronics could be
 it might be possible to schedule them at the same time, but in different classrooms and with different teachers.
 
 Timetables: The timetables for each division, indexed by the division ID.
+
+Slot: The position of a subjects group within its day is its slot, counted from 0, an empty subjects
+group marks a free slot, e.g. before a division's earliest start or a lunch break, so a lesson can be
+in slot 5 with slots 0-4 free, the slots after the last group of a day are free too, so days of a week
+may differ in length.
 */
 
 import (
+	"slices"
+
 	"smuggr.xyz/arrango/common/models/input"
 )
 
 type Subject struct {
-	GlobalSubject *input.GlobalSubject     `json:"global_subject,omitempty"`
-	Teacher       *input.Teacher           `json:"teacher,omitempty"`
-	Classroom     *input.Classroom         `json:"classroom,omitempty"`
-	Group         *input.SubjectsGroupType `json:"group,omitempty"`
+	GlobalSubject   *input.GlobalSubject     `json:"global_subject,omitempty"`
+	Teacher         *input.Teacher           `json:"teacher,omitempty"`
+	Classroom       *input.Classroom         `json:"classroom,omitempty"`
+	Group           *input.SubjectsGroupType `json:"group,omitempty"`
+	// Identifies the hours of a multi-hour block within a division's timetable, they must
+	// be placed in consecutive slots, 0 means the subject isn't a part of a block
+	Block           uint                     `json:"block,omitempty"`
+	// The classrooms reserved besides Classroom for subjects that need several rooms at once
+	ExtraClassrooms []*input.Classroom       `json:"extra_classrooms,omitempty"`
 }
 
-type SubjectsGroup [3]Subject       // A group of subjects, which are taught at the same time, maximum 3
+// Classrooms returns every classroom the lesson is taught in, Classroom first.
+func (s Subject) Classrooms() []*input.Classroom {
+	var rooms []*input.Classroom
+	if s.Classroom != nil {
+		rooms = append(rooms, s.Classroom)
+	}
+	for _, c := range s.ExtraClassrooms {
+		if c != nil {
+			rooms = append(rooms, c)
+		}
+	}
+	return rooms
+}
+
+type SubjectsGroup []Subject        // A group of subjects, which are taught at the same time, one per parallel group
 type Day           []SubjectsGroup  // A day's timetable
-type Days          [5]Day           // A week's timetable
+type Days          []Day            // A week's timetable, 5 days unless the solver is configured otherwise
 
 type OutputData struct {
 	// The timetables for each division, indexed by the division index
 	DivisionsTimetables []Days `json:"timetables,omitempty"`
+	// The fitness of the timetables, 0 means that all constraints are satisfied
+	Fitness             int    `json:"fitness"`
+}
+
+// IsEmpty reports whether no subject is placed in the subjects group, i.e. whether it marks a free slot.
+func (sg SubjectsGroup) IsEmpty() bool {
+	for _, subj := range sg {
+		if subj.GlobalSubject != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Slot returns the subjects group taught in the slot, or nil if the day is shorter.
+func (d Day) Slot(slot int) SubjectsGroup {
+	if slot < 0 || slot >= len(d) {
+		return nil
+	}
+	return d[slot]
+}
+
+// IsFree reports whether no subject is taught in the slot.
+func (d Day) IsFree(slot int) bool {
+	return d.Slot(slot).IsEmpty()
+}
+
+// Place returns the day with the subjects group in the slot, replacing whatever was taught
+// in it, a day shorter than that is extended with free slots first.
+func (d Day) Place(slot int, sg SubjectsGroup) Day {
+	for len(d) <= slot {
+		d = append(d, SubjectsGroup{})
+	}
+	d[slot] = sg
+	return d
+}
+
+// At returns the day's timetable, or nil if the week is shorter.
+func (d Days) At(day int) Day {
+	if day < 0 || day >= len(d) {
+		return nil
+	}
+	return d[day]
+}
+
+// Clone deep-copies the days, so changes to the copy never affect the original.
+func (d Days) Clone() Days {
+	if d == nil {
+		return nil
+	}
+	clone := make(Days, len(d))
+	for day := range d {
+		clone[day] = d[day].Clone()
+	}
+	return clone
+}
+
+// Clone deep-copies the day along with its subjects groups.
+func (d Day) Clone() Day {
+	if d == nil {
+		return nil
+	}
+	clone := make(Day, len(d))
+	for slot := range d {
+		clone[slot] = slices.Clone(d[slot])
+	}
+	return clone
+}
+
+// WeekLength returns the number of days in the longest timetable.
+func (data OutputData) WeekLength() int {
+	days := 0
+	for _, d := range data.DivisionsTimetables {
+		days = max(days, len(d))
+	}
+	return days
 }
\ No newline at end of file
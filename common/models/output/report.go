@@ -0,0 +1,364 @@
+// common/models/output/report.go
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"strings"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+/* Report
+A single self-contained HTML page meant for sharing results with stakeholders, it bundles:
+Master grid:   every division side by side for each day and slot.
+Divisions:     a grid per division, days as columns and slots as rows.
+Teachers:      a grid per teacher showing where and whom they teach.
+Fitness:       the fitness of the timetables, split into hard, soft and per-constraint penalties
+               when the solver provides them.
+Violations:    remaining conflicts, the solver's when it provides them, otherwise the ones found
+               in the timetables.
+*/
+
+// ReportDetails carries what the report can't compute from the output data alone, the solver
+// fills it since this package can't depend on it.
+type ReportDetails struct {
+	Hard       int          // The sum of the hard constraint penalties
+	Soft       int          // The sum of the soft constraint penalties
+	Terms      []ReportTerm // The penalty of every constraint, already weighted
+	Violations []string     // Every hard constraint violation, e.g. "Teacher LJ is booked 2 times on Tuesday slot 3"
+}
+
+// ReportTerm is the penalty of a single constraint, e.g. {"teacher gaps", false, 12}.
+type ReportTerm struct {
+	Name    string
+	Hard    bool
+	Penalty int
+}
+
+type reportGrid struct {
+	Title   string
+	Columns []string
+	Rows    []reportRow
+}
+
+type reportRow struct {
+	Label string
+	Cells [][]string // Lessons in each column, several when groups are taught in parallel
+}
+
+type reportPage struct {
+	Master     reportGrid
+	Divisions  []reportGrid
+	Teachers   []reportGrid
+	Fitness    int
+	Details    *ReportDetails
+	Violations []string
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Timetable report</title>
+<style>
+body { font-family: sans-serif; margin: 20px; }
+table { border-collapse: collapse; margin-bottom: 20px; width: 100%; }
+th, td { border: 1px solid #000; padding: 4px; vertical-align: top; }
+th { background: #eee; }
+td div { white-space: nowrap; }
+.ok { color: #2a7d2a; }
+.bad { color: #b22222; }
+</style>
+</head>
+<body>
+{{define "grid"}}
+<table>
+<caption>{{.Title}}</caption>
+<thead><tr><th></th>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Rows}}<tr><th>{{.Label}}</th>{{range .Cells}}<td>{{range .}}<div>{{.}}</div>{{end}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{end}}
+<h1>Timetable report</h1>
+<section id="fitness">
+<h2>Fitness</h2>
+<p class="{{if eq .Fitness 0}}ok{{else}}bad{{end}}">{{.Fitness}}</p>
+{{with .Details}}<table>
+<caption>Fitness breakdown</caption>
+<thead><tr><th>Constraint</th><th>Penalty</th></tr></thead>
+<tbody>
+<tr><th>Hard</th><td class="{{if eq .Hard 0}}ok{{else}}bad{{end}}">{{.Hard}}</td></tr>
+<tr><th>Soft</th><td>{{.Soft}}</td></tr>
+{{range .Terms}}<tr><td>{{.Name}}{{if .Hard}} (hard){{end}}</td><td>{{.Penalty}}</td></tr>
+{{end}}</tbody>
+</table>{{end}}
+</section>
+<section id="violations">
+<h2>Violations</h2>
+{{if .Violations}}<ul>{{range .Violations}}<li class="bad">{{.}}</li>{{end}}</ul>{{else}}<p class="ok">None</p>{{end}}
+</section>
+<section id="master">
+<h2>Master grid</h2>
+{{template "grid" .Master}}
+</section>
+<section id="divisions">
+<h2>Divisions</h2>
+{{range .Divisions}}{{template "grid" .}}{{end}}
+</section>
+<section id="teachers">
+<h2>Teachers</h2>
+{{range .Teachers}}{{template "grid" .}}{{end}}
+</section>
+</body>
+</html>
+`))
+
+// WriteReportHTML writes a self-contained HTML report of the solved timetables, details
+// may be nil, the report then shows just the fitness and the conflicts it finds itself.
+func WriteReportHTML(w io.Writer, result OutputData, in input.InputData, cal Calendar, details *ReportDetails) error {
+	cal = cal.ForInput(in)
+	page := reportPage{
+		Fitness: result.Fitness,
+		Details: details,
+	}
+	if details != nil {
+		page.Violations = details.Violations
+	} else {
+		page.Violations = reportViolations(result, in, cal)
+	}
+
+	weekDays := result.WeekLength()
+	maxSlots := 0
+	for _, days := range result.DivisionsTimetables {
+		for _, day := range days {
+			maxSlots = max(maxSlots, len(day))
+		}
+	}
+
+	// Master grid, a column per division and a row per day and slot
+	page.Master.Title = "All divisions"
+	for dIdx := range result.DivisionsTimetables {
+		page.Master.Columns = append(page.Master.Columns, divisionName(in, dIdx))
+	}
+	for day := 0; day < weekDays; day++ {
+		for slot := 0; slot < maxSlots; slot++ {
+			row := reportRow{Label: fmt.Sprintf("%s %d (%s)", cal.DayName(day), slot+1, cal.SlotLabel(slot))}
+			for _, days := range result.DivisionsTimetables {
+				row.Cells = append(row.Cells, lessonLabels(days.At(day), slot))
+			}
+			page.Master.Rows = append(page.Master.Rows, row)
+		}
+	}
+
+	// A grid per division
+	for dIdx, days := range result.DivisionsTimetables {
+		grid := reportGrid{Title: divisionName(in, dIdx), Columns: cal.dayNames(weekDays)}
+		for slot := 0; slot < maxSlots; slot++ {
+			row := reportRow{Label: fmt.Sprintf("%d (%s)", slot+1, cal.SlotLabel(slot))}
+			for day := 0; day < weekDays; day++ {
+				row.Cells = append(row.Cells, lessonLabels(days.At(day), slot))
+			}
+			grid.Rows = append(grid.Rows, row)
+		}
+		page.Divisions = append(page.Divisions, grid)
+	}
+
+	// A grid per teacher
+	teachers := slices.Clone(in.Teachers)
+	for _, days := range result.DivisionsTimetables {
+		for _, day := range days {
+			for _, sg := range day {
+				for _, subj := range sg {
+					if subj.GlobalSubject != nil && subj.Teacher != nil && !slices.Contains(teachers, *subj.Teacher) {
+						teachers = append(teachers, *subj.Teacher)
+					}
+				}
+			}
+		}
+	}
+	for _, teacher := range teachers {
+		grid := reportGrid{Title: string(teacher), Columns: cal.dayNames(weekDays)}
+		teaches := false
+		for slot := 0; slot < maxSlots; slot++ {
+			row := reportRow{Label: fmt.Sprintf("%d (%s)", slot+1, cal.SlotLabel(slot))}
+			for day := 0; day < weekDays; day++ {
+				var cell []string
+				for dIdx, days := range result.DivisionsTimetables {
+					for _, subj := range days.At(day).Slot(slot) {
+						if subj.GlobalSubject == nil || subj.Teacher == nil || *subj.Teacher != teacher {
+							continue
+						}
+						label := fmt.Sprintf("%s (%s)", *subj.GlobalSubject, divisionName(in, dIdx))
+						if rooms := classroomLabel(subj); rooms != "" {
+							label += " @" + rooms
+						}
+						cell = append(cell, label)
+						teaches = true
+					}
+				}
+				row.Cells = append(row.Cells, cell)
+			}
+			grid.Rows = append(grid.Rows, row)
+		}
+		if teaches {
+			page.Teachers = append(page.Teachers, grid)
+		}
+	}
+
+	return reportTemplate.Execute(w, page)
+}
+
+func divisionName(in input.InputData, dIdx int) string {
+	if dIdx < len(in.Divisions) && in.Divisions[dIdx].Name != "" {
+		return in.Divisions[dIdx].Name
+	}
+	return fmt.Sprintf("Division %d", dIdx)
+}
+
+// lessonLabels returns a label for each lesson taught in the slot of the day.
+func lessonLabels(day Day, slot int) []string {
+	var labels []string
+	for _, subj := range day.Slot(slot) {
+		if subj.GlobalSubject == nil {
+			continue
+		}
+		labels = append(labels, lessonLabel(subj))
+	}
+	return labels
+}
+
+// lessonLabel returns the subject, teacher and classroom of a placed subject, e.g. "matematyka LJ @7".
+func lessonLabel(subj Subject) string {
+	label := string(*subj.GlobalSubject)
+	if subj.Teacher != nil {
+		label += " " + string(*subj.Teacher)
+	}
+	if rooms := classroomLabel(subj); rooms != "" {
+		label += " @" + rooms
+	}
+	return label
+}
+
+// classroomLabel returns the names of the classrooms of a placed subject, e.g. "7" or "sj5+sj6".
+func classroomLabel(subj Subject) string {
+	var names []string
+	for _, c := range subj.Classrooms() {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, "+")
+}
+
+// reportViolations lists the conflicts that can be found in the output data alone,
+// double booked teachers and classrooms and invalid parallel groups, i.e. groups placed
+// twice in a slot, whole division lessons sharing a slot and slots with more than
+// MaxParallelGroups lessons.
+func reportViolations(data OutputData, in input.InputData, cal Calendar) []string {
+	var violations []string
+
+	type slotKey struct {
+		day  int
+		slot int
+	}
+	teachers := make(map[slotKey]map[input.Teacher]int)
+	classrooms := make(map[slotKey]map[string]int)
+	for _, days := range data.DivisionsTimetables {
+		for day, d := range days {
+			for slot, sg := range d {
+				key := slotKey{day: day, slot: slot}
+				for _, subj := range sg {
+					if subj.GlobalSubject == nil {
+						continue
+					}
+					if subj.Teacher != nil {
+						if teachers[key] == nil {
+							teachers[key] = make(map[input.Teacher]int)
+						}
+						teachers[key][*subj.Teacher]++
+					}
+					for _, c := range subj.Classrooms() {
+						if classrooms[key] == nil {
+							classrooms[key] = make(map[string]int)
+						}
+						classrooms[key][c.Name]++
+					}
+				}
+			}
+		}
+	}
+
+	for day := 0; day < data.WeekLength(); day++ {
+		for slot := 0; ; slot++ {
+			key := slotKey{day: day, slot: slot}
+			if teachers[key] == nil && classrooms[key] == nil && !slotUsed(data, day, slot) {
+				break
+			}
+			for _, teacher := range sortedKeys(teachers[key]) {
+				if n := teachers[key][teacher]; n > 1 {
+					violations = append(violations, fmt.Sprintf("Teacher %s is booked %d times on %s slot %d", teacher, n, cal.DayName(day), slot+1))
+				}
+			}
+			for _, classroom := range sortedKeys(classrooms[key]) {
+				if n := classrooms[key][classroom]; n > 1 {
+					violations = append(violations, fmt.Sprintf("Classroom %s is booked %d times on %s slot %d", classroom, n, cal.DayName(day), slot+1))
+				}
+			}
+		}
+	}
+
+	for dIdx, days := range data.DivisionsTimetables {
+		for day, d := range days {
+			for slot, sg := range d {
+				when := fmt.Sprintf("%s slot %d", cal.DayName(day), slot+1)
+				placed, whole := 0, false
+				var groups []input.SubjectsGroupType
+				for _, subj := range sg {
+					if subj.GlobalSubject == nil {
+						continue
+					}
+					placed++
+					if subj.Group == nil || *subj.Group == "" || *subj.Group == input.SubjectsGroupNone {
+						whole = true
+						continue
+					}
+					if slices.Contains(groups, *subj.Group) {
+						violations = append(violations, fmt.Sprintf("Division %s: group %s of %s is placed twice on %s",
+							divisionName(in, dIdx), *subj.Group, *subj.GlobalSubject, when))
+					} else {
+						groups = append(groups, *subj.Group)
+					}
+				}
+				if whole && placed > 1 {
+					violations = append(violations, fmt.Sprintf("Division %s: a whole division lesson shares %s with other groups",
+						divisionName(in, dIdx), when))
+				}
+				if placed > MaxParallelGroups {
+					violations = append(violations, fmt.Sprintf("Division %s: %d lessons on %s, at most %d groups are taught in parallel",
+						divisionName(in, dIdx), placed, when, MaxParallelGroups))
+				}
+			}
+		}
+	}
+	return violations
+}
+
+func slotUsed(data OutputData, day, slot int) bool {
+	for _, days := range data.DivisionsTimetables {
+		if slot < len(days.At(day)) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[K ~string, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+// common/models/output/report_test.go
+package output
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWriteReportHTML(t *testing.T) {
+	data, in := sampleData()
+	var b strings.Builder
+	if err := WriteReportHTML(&b, data, in, DefaultCalendar, nil); err != nil {
+		t.Fatal(err)
+	}
+	page := b.String()
+
+	for _, want := range []string{
+		`<section id="fitness">`,
+		`<section id="violations">`,
+		`<section id="master">`,
+		`<section id="divisions">`,
+		`<section id="teachers">`,
+		"<caption>All divisions</caption>",
+		"<caption>1A</caption>",
+		"<caption>LJ</caption>",
+		"<caption>AK</caption>",
+		`<p class="bad">3</p>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report doesn't contain %s", want)
+		}
+	}
+	if strings.Contains(page, "<link") || strings.Contains(page, "<script src") {
+		t.Error("report isn't self-contained")
+	}
+}
+
+func TestWriteReportHTMLDetails(t *testing.T) {
+	data, in := sampleData()
+	details := &ReportDetails{
+		Hard:       1000,
+		Soft:       3,
+		Terms:      []ReportTerm{{Name: "teacher_overlaps", Hard: true, Penalty: 1000}, {Name: "teacher_gaps", Penalty: 3}},
+		Violations: []string{"Teacher LJ is booked 2 times on Monday slot 2"},
+	}
+	var b strings.Builder
+	if err := WriteReportHTML(&b, data, in, DefaultCalendar, details); err != nil {
+		t.Fatal(err)
+	}
+	page := b.String()
+
+	for _, want := range []string{
+		"<caption>Fitness breakdown</caption>",
+		`<tr><th>Hard</th><td class="bad">1000</td></tr>`,
+		"<tr><th>Soft</th><td>3</td></tr>",
+		"<tr><td>teacher_overlaps (hard)</td><td>1000</td></tr>",
+		"<tr><td>teacher_gaps</td><td>3</td></tr>",
+		`<li class="bad">Teacher LJ is booked 2 times on Monday slot 2</li>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("report doesn't contain %s", want)
+		}
+	}
+}
+
+func TestReportViolations(t *testing.T) {
+	data, in := sampleData()
+	// Both english groups become group two, taught by LJ
+	english := data.DivisionsTimetables[0][0][1]
+	english[0].Group, english[0].Teacher = english[1].Group, english[1].Teacher
+	cal := Calendar{DayNames: []string{"Pon"}}
+
+	got := reportViolations(data, in, cal)
+	want := []string{
+		"Teacher LJ is booked 2 times on Pon slot 2",
+		"Division 1A: group two of angielski is placed twice on Pon slot 2",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("reportViolations() = %q, want %q", got, want)
+	}
+}
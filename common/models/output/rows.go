@@ -0,0 +1,73 @@
+// common/models/output/rows.go
+package output
+
+import (
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+// A single flat, fully keyed row of a timetable, the easiest shape to insert into a relational database
+type TimetableRow struct {
+	DivisionIndex int    `json:"division_index"`
+	DivisionName  string `json:"division_name"`
+	Day           int    `json:"day"`
+	Slot          int    `json:"slot"`
+	GroupIndex    int    `json:"group_index"` // The index of the subject within the subjects group
+	GlobalSubject string `json:"global_subject"`
+	Teacher       string `json:"teacher"`
+	Classroom     string `json:"classroom"`
+}
+
+// Rows flattens the output data into a row per placed subject, empty slots are omitted.
+func Rows(data OutputData, in input.InputData) []TimetableRow {
+	return rows(data, in, false)
+}
+
+// RowsWithEmpty flattens the output data like Rows, but also includes a row with
+// empty subject, teacher and classroom for every empty slot.
+func RowsWithEmpty(data OutputData, in input.InputData) []TimetableRow {
+	return rows(data, in, true)
+}
+
+func rows(data OutputData, in input.InputData, includeEmpty bool) []TimetableRow {
+	var result []TimetableRow
+
+	for dIdx, days := range data.DivisionsTimetables {
+		name := ""
+		if dIdx < len(in.Divisions) {
+			name = in.Divisions[dIdx].Name
+		}
+
+		for day, d := range days {
+			for slot, sg := range d {
+				empty := true
+				for gIdx, subj := range sg {
+					if subj.GlobalSubject == nil {
+						continue
+					}
+					empty = false
+					lesson := frontendLesson(subj)
+					result = append(result, TimetableRow{
+						DivisionIndex: dIdx,
+						DivisionName:  name,
+						Day:           day,
+						Slot:          slot,
+						GroupIndex:    gIdx,
+						GlobalSubject: lesson.Subject,
+						Teacher:       lesson.Teacher,
+						Classroom:     lesson.Classroom,
+					})
+				}
+				if empty && includeEmpty {
+					result = append(result, TimetableRow{
+						DivisionIndex: dIdx,
+						DivisionName:  name,
+						Day:           day,
+						Slot:          slot,
+					})
+				}
+			}
+		}
+	}
+
+	return result
+}
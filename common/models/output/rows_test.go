@@ -0,0 +1,41 @@
+// common/models/output/rows_test.go
+package output
+
+import (
+	"testing"
+)
+
+func TestRows(t *testing.T) {
+	data, in := sampleData()
+
+	lessons, free := 0, 0
+	for _, days := range data.DivisionsTimetables {
+		for _, day := range days {
+			for _, sg := range day {
+				placed := 0
+				for _, subj := range sg {
+					if subj.GlobalSubject != nil {
+						placed++
+					}
+				}
+				if placed == 0 {
+					free++
+				}
+				lessons += placed
+			}
+		}
+	}
+
+	rows := Rows(data, in)
+	if len(rows) != lessons {
+		t.Errorf("%d rows, want one per each of the %d placed lessons", len(rows), lessons)
+	}
+	if n := len(RowsWithEmpty(data, in)); n != lessons+free {
+		t.Errorf("%d rows with empty slots, want %d", n, lessons+free)
+	}
+
+	want := TimetableRow{DivisionIndex: 0, DivisionName: "1A", Day: 0, Slot: 1, GroupIndex: 1, GlobalSubject: "angielski", Teacher: "LJ", Classroom: "12"}
+	if rows[2] != want {
+		t.Errorf("row = %+v, want %+v", rows[2], want)
+	}
+}
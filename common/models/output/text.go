@@ -0,0 +1,105 @@
+// common/models/output/text.go
+package output
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+// Format returns the timetables as aligned plain text, meant for logs and debugging, with a
+// grid per division that has the days as columns and the slots as rows, every lesson is shown
+// as "subject/teacher@room" and lessons taught at the same time are stacked within the cell.
+func Format(data OutputData, in input.InputData) string {
+	var b strings.Builder
+	weekDays := data.WeekLength()
+	fmt.Fprintf(&b, "Fitness: %d\n", data.Fitness)
+
+	for dIdx, days := range data.DivisionsTimetables {
+		slots := 0
+		for _, day := range days {
+			slots = max(slots, len(day))
+		}
+
+		// The first row holds the day names, every slot takes as many rows as its busiest cell
+		rows := [][]string{append([]string{""}, DefaultCalendar.dayNames(weekDays)...)}
+		for slot := 0; slot < slots; slot++ {
+			cells := make([][]string, weekDays)
+			height := 1
+			for day := range cells {
+				cells[day] = textLabels(days.At(day), slot)
+				height = max(height, len(cells[day]))
+			}
+			for line := 0; line < height; line++ {
+				row := []string{""}
+				if line == 0 {
+					row[0] = slotRowLabel(in, slot)
+				}
+				for _, cell := range cells {
+					if line < len(cell) {
+						row = append(row, cell[line])
+					} else {
+						row = append(row, "")
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+
+		widths := make([]int, weekDays+1)
+		for _, row := range rows {
+			for col, cell := range row {
+				widths[col] = max(widths[col], utf8.RuneCountInString(cell))
+			}
+		}
+
+		fmt.Fprintf(&b, "\n%s\n", divisionName(in, dIdx))
+		for i, row := range rows {
+			writeTextRow(&b, row, widths)
+			if i == 0 {
+				separator := make([]string, len(widths))
+				for col, w := range widths {
+					separator[col] = strings.Repeat("-", w)
+				}
+				b.WriteString(strings.Join(separator, "-+-") + "\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// String formats the timetables with Format, without input data the divisions are named
+// by their index and the slots by their number.
+func (data OutputData) String() string {
+	return Format(data, input.InputData{})
+}
+
+// writeTextRow writes the cells of a row padded to the widths of their columns.
+func writeTextRow(b *strings.Builder, row []string, widths []int) {
+	line := make([]string, len(row))
+	for col, cell := range row {
+		line[col] = cell + strings.Repeat(" ", widths[col]-utf8.RuneCountInString(cell))
+	}
+	b.WriteString(strings.TrimRight(strings.Join(line, " | "), " ") + "\n")
+}
+
+// textLabels returns a "subject/teacher@room" label for each lesson taught in the slot of the day.
+func textLabels(day Day, slot int) []string {
+	var labels []string
+	for _, subj := range day.Slot(slot) {
+		if subj.GlobalSubject == nil {
+			continue
+		}
+		label := string(*subj.GlobalSubject)
+		if subj.Teacher != nil {
+			label += "/" + string(*subj.Teacher)
+		}
+		if rooms := classroomLabel(subj); rooms != "" {
+			label += "@" + rooms
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
@@ -0,0 +1,9 @@
+// common/models/output/text_test.go
+package output
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	data, in := sampleData()
+	golden(t, "format.golden.txt", []byte(Format(data, in)))
+}
@@ -0,0 +1,64 @@
+// common/models/output/verify.go
+package output
+
+import (
+	"fmt"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+// The maximum number of groups that can be taught in parallel in a single slot,
+// one per group of input.AllGroups besides the whole division
+const MaxParallelGroups = 4
+
+// Verify checks the output data as a final safety net before returning results,
+// every slot holds at most MaxParallelGroups parallel groups, and parallel groups
+// within a slot belong to the same global subject and have distinct groups.
+func Verify(data OutputData) []error {
+	var errs []error
+
+	for dIdx, days := range data.DivisionsTimetables {
+		for day, d := range days {
+			for slot, sg := range d {
+				where := fmt.Sprintf("division %d, day %d, slot %d", dIdx, day, slot)
+
+				var placed []Subject
+				for _, subj := range sg {
+					if subj.GlobalSubject != nil {
+						placed = append(placed, subj)
+					}
+				}
+				if len(placed) > MaxParallelGroups {
+					errs = append(errs, fmt.Errorf("%s: %d parallel groups, at most %d are allowed",
+						where, len(placed), MaxParallelGroups))
+				}
+				if len(placed) < 2 {
+					continue
+				}
+
+				groups := make(map[input.SubjectsGroupType]bool)
+				for _, subj := range placed[1:] {
+					if *subj.GlobalSubject != *placed[0].GlobalSubject {
+						errs = append(errs, fmt.Errorf("%s: parallel groups mix subjects %q and %q",
+							where, *placed[0].GlobalSubject, *subj.GlobalSubject))
+					}
+				}
+				for _, subj := range placed {
+					group := input.SubjectsGroupNone
+					if subj.Group != nil {
+						group = *subj.Group
+					}
+					if group == input.SubjectsGroupNone {
+						errs = append(errs, fmt.Errorf("%s: %q is taught to the whole division but shares the slot with other groups",
+							where, *subj.GlobalSubject))
+					} else if groups[group] {
+						errs = append(errs, fmt.Errorf("%s: group %q of %q is placed twice", where, group, *subj.GlobalSubject))
+					}
+					groups[group] = true
+				}
+			}
+		}
+	}
+
+	return errs
+}
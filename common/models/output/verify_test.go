@@ -0,0 +1,35 @@
+// common/models/output/verify_test.go
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestVerify(t *testing.T) {
+	english, math := input.GlobalSubject("angielski"), input.GlobalSubject("matematyka")
+	one, two, three := input.SubjectsGroupOne, input.SubjectsGroupTwo, input.SubjectsGroupThree
+	slot := func(sg SubjectsGroup) OutputData {
+		return OutputData{DivisionsTimetables: []Days{{{sg}}}}
+	}
+
+	valid := slot(SubjectsGroup{
+		{GlobalSubject: &english, Group: &one},
+		{GlobalSubject: &english, Group: &two},
+		{GlobalSubject: &english, Group: &three},
+	})
+	if errs := Verify(valid); len(errs) != 0 {
+		t.Errorf("three parallel groups: %v, want no errors", errs)
+	}
+
+	mixed := slot(SubjectsGroup{
+		{GlobalSubject: &english, Group: &one},
+		{GlobalSubject: &math, Group: &two},
+	})
+	errs := Verify(mixed)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "mix subjects") {
+		t.Errorf("mixed subjects: %v, want a single mix error", errs)
+	}
+}
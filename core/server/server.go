@@ -0,0 +1,110 @@
+// core/server/server.go
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/core/solver"
+)
+
+/* Endpoints
+POST /solve         Solves the InputData in the request body and responds with the OutputData.
+POST /solve/stream  Like /solve, but responds with server-sent events, a "progress" event with
+                    the best fitness so far after every generation and a final "result" event
+                    with the OutputData, or an "error" event with a message when the solve
+                    couldn't run.
+Both stop solving when the client disconnects.
+*/
+
+// The largest request body that is accepted, in bytes
+const maxInputSize = 10 << 20
+
+// A progress event of /solve/stream
+type Progress struct {
+	Generation int `json:"generation"`
+	Fitness    int `json:"fitness"`
+}
+
+// Handler returns the HTTP handler of the service, every request is solved by its own
+// copy of the given solver.
+func Handler(s solver.Solver) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /solve", func(w http.ResponseWriter, r *http.Request) {
+		in, ok := decodeInput(w, r)
+		if !ok {
+			return
+		}
+
+		result := s.SolveContext(r.Context(), in)
+		if r.Context().Err() != nil {
+			return // Nobody is listening anymore
+		}
+		if result.Err != nil {
+			// The solver is the service's own, so it's not the request's fault
+			http.Error(w, result.Err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result.Output); err != nil {
+			log.Printf("Error writing response: %v", err)
+		}
+	})
+	mux.HandleFunc("POST /solve/stream", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+		in, ok := decodeInput(w, r)
+		if !ok {
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		// The solve runs in this goroutine, so the callback can write to the response directly
+		rs := s
+		rs.OnGeneration = func(gen int, bestFitness int) {
+			writeEvent(w, "progress", Progress{Generation: gen, Fitness: bestFitness})
+			flusher.Flush()
+		}
+
+		result := rs.SolveContext(r.Context(), in)
+		if r.Context().Err() != nil {
+			return
+		}
+		if result.Err != nil {
+			writeEvent(w, "error", result.Err.Error())
+		} else {
+			writeEvent(w, "result", result.Output)
+		}
+		flusher.Flush()
+	})
+	return mux
+}
+
+// decodeInput reads the input data from the request body, it responds with an error
+// and returns false if the body isn't valid input data.
+func decodeInput(w http.ResponseWriter, r *http.Request) (input.InputData, bool) {
+	in, err := input.Decode(http.MaxBytesReader(w, r.Body, maxInputSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return input.InputData{}, false
+	}
+	return in, true
+}
+
+func writeEvent(w http.ResponseWriter, event string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error encoding %s event: %v", event, err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
@@ -0,0 +1,84 @@
+// core/server/server_test.go
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+	"smuggr.xyz/arrango/core/solver"
+)
+
+// post posts the body to the path of a test server solving a few generations.
+func post(t *testing.T, path string, body io.Reader) *http.Response {
+	t.Helper()
+	srv := httptest.NewServer(Handler(solver.Solver{PopulationSize: 10, Generations: 5, MutationRate: 0.1, Seed: 1}))
+	t.Cleanup(srv.Close)
+	resp, err := http.Post(srv.URL+path, "application/json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// exampleBody returns the example input data as JSON.
+func exampleBody(t *testing.T) io.Reader {
+	t.Helper()
+	body, err := json.Marshal(input.ExampleInputData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(body)
+}
+
+func TestSolve(t *testing.T) {
+	resp := post(t, "/solve", exampleBody(t))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %s, want 200 OK", resp.Status)
+	}
+	var out output.OutputData
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("response isn't OutputData: %v", err)
+	}
+	if len(out.DivisionsTimetables) != len(input.ExampleInputData.Divisions) {
+		t.Errorf("%d timetables, want one per division", len(out.DivisionsTimetables))
+	}
+}
+
+func TestSolveStream(t *testing.T) {
+	resp := post(t, "/solve/stream", exampleBody(t))
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("content type = %q, want text/event-stream", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every generation reports its progress before the result, unless the solve stops early
+	stream := string(body)
+	if n := strings.Count(stream, "event: progress\n"); n == 0 || n > 5 {
+		t.Errorf("%d progress events, want one per generation, at most 5", n)
+	}
+	_, result, ok := strings.Cut(stream, "event: result\ndata: ")
+	if !ok {
+		t.Fatalf("no result event in %q", stream)
+	}
+	var out output.OutputData
+	if err := json.Unmarshal([]byte(strings.TrimSpace(result)), &out); err != nil {
+		t.Errorf("result event isn't OutputData: %v", err)
+	}
+}
+
+func TestSolveBadInput(t *testing.T) {
+	if resp := post(t, "/solve", strings.NewReader("{")); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %s, want 400 Bad Request", resp.Status)
+	}
+}
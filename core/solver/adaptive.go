@@ -0,0 +1,37 @@
+// core/solver/adaptive.go
+package solver
+
+const (
+	// The fraction of the distance to MutationRateMax the rate rises by in a stagnant generation
+	mutationRateRise = 0.1
+	// The fraction of the distance to MutationRateMin the rate decays by after an improvement
+	mutationRateDecay = 0.5
+)
+
+// adaptive reports whether the mutation rate adapts between its bounds.
+func (s *Solver) adaptive() bool {
+	return s.MutationRateMax > s.MutationRateMin
+}
+
+// initialMutationRate returns the rate the first generation is mutated with.
+func (s *Solver) initialMutationRate() float64 {
+	if s.adaptive() {
+		return s.MutationRateMin
+	}
+	return s.MutationRate
+}
+
+// adaptMutationRate returns the rate of the next generation, it rises towards
+// MutationRateMax while the best individual stagnates, to escape local optima,
+// and falls back towards MutationRateMin once it improves.
+func (s *Solver) adaptMutationRate(rate float64, improved bool) float64 {
+	if !s.adaptive() {
+		return s.MutationRate
+	}
+	if improved {
+		rate -= (rate - s.MutationRateMin) * mutationRateDecay
+	} else {
+		rate += (s.MutationRateMax - rate) * mutationRateRise
+	}
+	return min(max(rate, s.MutationRateMin), s.MutationRateMax)
+}
@@ -0,0 +1,30 @@
+// core/solver/adaptive_test.go
+package solver
+
+import "testing"
+
+func TestAdaptMutationRate(t *testing.T) {
+	s := &Solver{MutationRate: 0.1, MutationRateMin: 0.05, MutationRateMax: 0.5}
+	rate := s.initialMutationRate()
+	if rate != s.MutationRateMin {
+		t.Fatalf("initial rate = %v, want the minimum %v", rate, s.MutationRateMin)
+	}
+
+	// The rate rises with every stagnant generation without passing the maximum
+	for gen := range 20 {
+		next := s.adaptMutationRate(rate, false)
+		if next <= rate || next > s.MutationRateMax {
+			t.Fatalf("stagnant generation %d: rate %v -> %v, want a rise up to %v", gen, rate, next, s.MutationRateMax)
+		}
+		rate = next
+	}
+	if next := s.adaptMutationRate(rate, true); next >= rate || next < s.MutationRateMin {
+		t.Errorf("after an improvement rate %v -> %v, want a decay down to %v", rate, next, s.MutationRateMin)
+	}
+
+	// Equal bounds keep the constant rate
+	constant := &Solver{MutationRate: 0.1}
+	if rate := constant.adaptMutationRate(constant.initialMutationRate(), false); rate != 0.1 {
+		t.Errorf("constant rate = %v after a stagnant generation, want 0.1", rate)
+	}
+}
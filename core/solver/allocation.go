@@ -0,0 +1,144 @@
+// core/solver/allocation.go
+package solver
+
+import (
+	"math/rand"
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+/* Allocation mutation
+Swaps and moves only rearrange the hours an individual already has, so a child that lost an
+hour could only get it back from a luckier parent. Now and then a mutation looks for a division
+whose timetable doesn't match its allocation instead, it places the missing hours of an unmet
+chunk together at the end of the least loaded day, as randomIndividual would, or drops a surplus
+hour of a subject placed more often than it's allocated. Repair closes the gap a dropped hour
+leaves. Individuals that match their allocations get an ordinary mutation.
+*/
+
+// The share of mutations that try to fix an allocation before swapping or moving lessons
+const allocationMutationRate = 0.5
+
+// fixAllocation places the missing hours of a random unmet chunk, or removes a surplus hour,
+// of the first division, starting from a random one, whose timetable doesn't match its
+// allocation, it reports whether the individual was changed.
+func (s *Solver) fixAllocation(rng *rand.Rand, ind *Individual, in input.InputData) bool {
+	n := min(len(ind.Timetables), len(in.Divisions))
+	if n == 0 {
+		return false
+	}
+	start := rng.Intn(n)
+	for i := 0; i < n; i++ {
+		dx := (start + i) % n
+		if s.placeUnmetChunk(rng, ind, in.Divisions[dx], dx) || s.removeSurplusHour(rng, ind, in.Divisions[dx], dx) {
+			return true
+		}
+	}
+	return false
+}
+
+// placeUnmetChunk places the missing hours of a random unmet chunk of the division as a
+// block appended to the least loaded free day.
+func (s *Solver) placeUnmetChunk(rng *rand.Rand, ind *Individual, div input.Division, dx int) bool {
+	var unmet []subjectChunk
+	for _, c := range s.unmetChunks(ind.Timetables[dx], div) {
+		if c.size > 0 {
+			unmet = append(unmet, c)
+		}
+	}
+	if len(unmet) == 0 || len(s.freeDays()) == 0 {
+		return false
+	}
+	chunk := unmet[rng.Intn(len(unmet))]
+
+	days := ind.Timetables[dx]
+	day := s.pickLeastLoadedDay(days, div, chunk)
+	if day >= len(days) {
+		return false
+	}
+	var chunkBlock uint
+	if chunk.size > 1 {
+		chunkBlock = maxBlock(days) + 1
+	}
+
+	d := days[day]
+	if len(d) == 0 && div.EarliestStart > 0 {
+		d = make(output.Day, div.EarliestStart)
+	}
+	for i := uint(0); i < chunk.size; i++ {
+		classroom := s.pickClassroom(rng, chunk.subj)
+		d = append(d, output.SubjectsGroup{{
+			GlobalSubject:   chunk.subj.GlobalSubject,
+			Teacher:         chunk.subj.Teacher,
+			Classroom:       classroom,
+			Group:           &chunk.subj.Group,
+			Block:           chunkBlock,
+			ExtraClassrooms: s.pickExtraClassrooms(rng, chunk.subj, classroom),
+		}})
+	}
+	days[day] = d
+	return true
+}
+
+// removeSurplusHour removes a random hour, placed in a free day, of a subject the division
+// has more hours of than its allocation.
+func (s *Solver) removeSurplusHour(rng *rand.Rand, ind *Individual, div input.Division, dx int) bool {
+	type subjectKey struct {
+		globalSubject input.GlobalSubject
+		teacher       input.Teacher
+	}
+	surplus := make(map[subjectKey]int)
+	for _, subj := range div.Subjects {
+		key := subjectKey{deref(subj.GlobalSubject), deref(subj.Teacher)}
+		for _, alloc := range subj.Allocation {
+			surplus[key] -= int(alloc)
+		}
+	}
+	days := ind.Timetables[dx]
+	for _, d := range days {
+		for _, sg := range d {
+			for _, subj := range sg {
+				if subj.GlobalSubject != nil {
+					surplus[subjectKey{deref(subj.GlobalSubject), deref(subj.Teacher)}]++
+				}
+			}
+		}
+	}
+
+	type hour struct{ day, slot, pos int }
+	var candidates []hour
+	for _, day := range s.freeDays() {
+		if day >= len(days) {
+			continue
+		}
+		for slot, sg := range days[day] {
+			for pos, subj := range sg {
+				if subj.GlobalSubject != nil && surplus[subjectKey{deref(subj.GlobalSubject), deref(subj.Teacher)}] > 0 {
+					candidates = append(candidates, hour{day, slot, pos})
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+
+	h := candidates[rng.Intn(len(candidates))]
+	days[h.day][h.slot] = slices.Delete(days[h.day][h.slot], h.pos, h.pos+1)
+	return true
+}
+
+// maxBlock returns the highest block number among the lessons of the days.
+func maxBlock(days output.Days) uint {
+	var block uint
+	for _, d := range days {
+		for _, sg := range d {
+			for _, subj := range sg {
+				block = max(block, subj.Block)
+			}
+		}
+	}
+	return block
+}
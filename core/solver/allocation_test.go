@@ -0,0 +1,38 @@
+// core/solver/allocation_test.go
+package solver
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestFixAllocation(t *testing.T) {
+	in := input.ExampleInputData
+	s := &Solver{}
+	rng := rand.New(rand.NewSource(1))
+	complete := s.randomIndividual(rng, in)
+
+	// Tuesday of the first division is lost, and one of its Monday lessons is doubled
+	ind := complete.Clone()
+	ind.Timetables[0][1] = nil
+	monday := ind.Timetables[0][0]
+	ind.Timetables[0][0] = append(monday, slices.Clone(monday[len(monday)-1]))
+	if v := s.hardViolations(ind, in); v.UnmetHours == 0 {
+		t.Fatal("dropping a day left no unmet hours")
+	}
+
+	for range 200 {
+		s.mutate(rng, &ind, in, 1)
+		s.repair(&ind)
+	}
+	if v := s.hardViolations(ind, in); v.UnmetHours != 0 {
+		t.Errorf("%d hours are still unmet after mutating", v.UnmetHours)
+	}
+	if !maps.Equal(lessonCounts(ind), lessonCounts(complete)) {
+		t.Error("mutations didn't restore the hours of every subject")
+	}
+}
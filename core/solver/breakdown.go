@@ -0,0 +1,224 @@
+// core/solver/breakdown.go
+package solver
+
+import (
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+// FitnessBreakdown splits the fitness of an individual into the penalty of every constraint,
+// already weighted, so e.g. a fitness of 3000 can be told apart as three teacher overlaps
+type FitnessBreakdown struct {
+	// Hard constraints
+	TeacherOverlaps   int `json:"teacher_overlaps"`
+	ClassroomOverlaps int `json:"classroom_overlaps"`
+	UnmetAllocation   int `json:"unmet_allocation"`
+	TeacherConflicts  int `json:"teacher_conflicts"`
+	ForbiddenDays     int `json:"forbidden_days"`
+	EarlyLessons      int `json:"early_lessons"`
+	SharedWholeSlots  int `json:"shared_whole_slots"`
+	ParallelClashes   int `json:"parallel_clashes"`
+	CapacityOverflows int `json:"capacity_overflows"`
+	DivisionGaps      int `json:"division_gaps"`
+	SplitBlocks       int `json:"split_blocks"`
+	UnavailableSlots  int `json:"unavailable_slots"`
+	DuplicateGroups   int `json:"duplicate_groups"`
+	MissedLunches     int `json:"missed_lunches"`
+	LateLessons       int `json:"late_lessons"`
+	OverfullSlots     int `json:"overfull_slots"`
+
+	// Soft constraints
+	Imbalance           int `json:"imbalance"`
+	FrontLoad           int `json:"front_load"`
+	Placement           int `json:"placement"`
+	SubjectsPerDay      int `json:"subjects_per_day"`
+	TimeBand            int `json:"time_band"`
+	TeacherGaps         int `json:"teacher_gaps"`
+	WorkDays            int `json:"work_days"`
+	RepeatedDays        int `json:"repeated_days"`
+	LateStart           int `json:"late_start"`
+	ConsecutiveHours    int `json:"consecutive_hours"`
+	PreferredClassrooms int `json:"preferred_classrooms"`
+	TeacherHours        int `json:"teacher_hours"`
+	ShortDays           int `json:"short_days"`
+	PreferredDivisions  int `json:"preferred_divisions"`
+	PreferredDays       int `json:"preferred_days"`
+	LunchBreaks         int `json:"lunch_breaks"`
+	BuildingChanges     int `json:"building_changes"`
+	LateSubjects        int `json:"late_subjects"`
+	SubjectPairs        int `json:"subject_pairs"`
+}
+
+// Hard returns the sum of the hard constraint penalties.
+func (b FitnessBreakdown) Hard() int {
+	return b.TeacherOverlaps + b.ClassroomOverlaps + b.UnmetAllocation + b.TeacherConflicts +
+		b.ForbiddenDays + b.EarlyLessons + b.SharedWholeSlots + b.ParallelClashes +
+		b.CapacityOverflows + b.DivisionGaps + b.SplitBlocks + b.UnavailableSlots +
+		b.DuplicateGroups + b.MissedLunches + b.LateLessons + b.OverfullSlots
+}
+
+// Soft returns the sum of the soft constraint penalties.
+func (b FitnessBreakdown) Soft() int {
+	return b.Imbalance + b.FrontLoad + b.Placement + b.SubjectsPerDay + b.TimeBand +
+		b.TeacherGaps + b.WorkDays + b.RepeatedDays + b.LateStart + b.ConsecutiveHours +
+		b.PreferredClassrooms + b.TeacherHours + b.ShortDays + b.PreferredDivisions +
+		b.PreferredDays + b.LunchBreaks + b.BuildingChanges + b.LateSubjects +
+		b.SubjectPairs
+}
+
+// Total returns the fitness, the sum of all penalties.
+func (b FitnessBreakdown) Total() int {
+	return b.Hard() + b.Soft()
+}
+
+// Terms lists the penalty of every constraint, hard constraints first, named as in the JSON.
+func (b FitnessBreakdown) Terms() []output.ReportTerm {
+	term := func(name string, hard bool, penalty int) output.ReportTerm {
+		return output.ReportTerm{Name: name, Hard: hard, Penalty: penalty}
+	}
+	return []output.ReportTerm{
+		term("teacher_overlaps", true, b.TeacherOverlaps),
+		term("classroom_overlaps", true, b.ClassroomOverlaps),
+		term("unmet_allocation", true, b.UnmetAllocation),
+		term("teacher_conflicts", true, b.TeacherConflicts),
+		term("forbidden_days", true, b.ForbiddenDays),
+		term("early_lessons", true, b.EarlyLessons),
+		term("shared_whole_slots", true, b.SharedWholeSlots),
+		term("parallel_clashes", true, b.ParallelClashes),
+		term("capacity_overflows", true, b.CapacityOverflows),
+		term("division_gaps", true, b.DivisionGaps),
+		term("split_blocks", true, b.SplitBlocks),
+		term("unavailable_slots", true, b.UnavailableSlots),
+		term("duplicate_groups", true, b.DuplicateGroups),
+		term("missed_lunches", true, b.MissedLunches),
+		term("late_lessons", true, b.LateLessons),
+		term("overfull_slots", true, b.OverfullSlots),
+		term("imbalance", false, b.Imbalance),
+		term("front_load", false, b.FrontLoad),
+		term("placement", false, b.Placement),
+		term("subjects_per_day", false, b.SubjectsPerDay),
+		term("time_band", false, b.TimeBand),
+		term("teacher_gaps", false, b.TeacherGaps),
+		term("work_days", false, b.WorkDays),
+		term("repeated_days", false, b.RepeatedDays),
+		term("late_start", false, b.LateStart),
+		term("consecutive_hours", false, b.ConsecutiveHours),
+		term("preferred_classrooms", false, b.PreferredClassrooms),
+		term("teacher_hours", false, b.TeacherHours),
+		term("short_days", false, b.ShortDays),
+		term("preferred_divisions", false, b.PreferredDivisions),
+		term("preferred_days", false, b.PreferredDays),
+		term("lunch_breaks", false, b.LunchBreaks),
+		term("building_changes", false, b.BuildingChanges),
+		term("late_subjects", false, b.LateSubjects),
+		term("subject_pairs", false, b.SubjectPairs),
+	}
+}
+
+// Evaluate scores an individual like the fitness, but keeps the penalty of every constraint apart.
+func (s *Solver) Evaluate(ind Individual, in input.InputData) FitnessBreakdown {
+	var b FitnessBreakdown
+	s.addHard(&b, ind, in)
+	s.addSoft(&b, ind, in)
+	return b
+}
+
+// addViolations adds the penalties of the violation counts, multiplied by weight.
+func (b *FitnessBreakdown) addViolations(v HardViolations, w FitnessWeights, weight int) {
+	b.TeacherOverlaps += v.TeacherOverlaps * w.TeacherOverlap * weight       // Teacher overlaps
+	b.ClassroomOverlaps += v.ClassroomOverlaps * w.ClassroomOverlap * weight // Classroom overlaps
+	b.TeacherConflicts += v.TeacherConflicts * w.TeacherConflict * weight    // Conflicting teachers teaching at the same time
+	b.ForbiddenDays += v.ForbiddenDays * w.ForbiddenDay * weight             // Subjects placed on their forbidden days
+	b.EarlyLessons += v.EarlyLessons * w.EarlyLesson * weight                // Lessons placed before their division's earliest start
+	b.SharedWholeSlots += v.SharedWholeSlots * w.SharedWholeSlot * weight    // Whole division subjects taught in parallel with other groups
+	b.ParallelClashes += v.ParallelClashes * w.ParallelClash * weight        // Parallel groups sharing a teacher or classroom
+	b.CapacityOverflows += v.CapacityOverflows * w.CapacityOverflow * weight // Classrooms too small for their students
+	b.DivisionGaps += v.DivisionGaps * w.DivisionGap * weight                // Gaps in timetables of divisions
+	b.SplitBlocks += v.SplitBlocks * w.SplitBlock * weight                   // Multi-hour blocks split across non-consecutive slots
+	b.UnavailableSlots += v.UnavailableSlots * w.UnavailableSlot * weight    // Teachers scheduled while unavailable
+	b.DuplicateGroups += v.DuplicateGroups * w.DuplicateGroup * weight       // Groups with two lessons in the same slot
+	b.MissedLunches += v.MissedLunches * w.LunchBreak * weight               // Days booked solid through the lunch window
+	b.LateLessons += v.LateLessons * w.LateLesson * weight                   // Lessons placed after their division's last slot
+	b.OverfullSlots += v.OverfullSlots * w.OverfullSlot * weight             // Parallel lessons beyond what a slot can hold
+	b.UnmetAllocation += v.UnmetHours * w.UnmetHour * weight                 // Penalty for not meeting required allocations
+}
+
+// addHard adds the hard constraint penalties, the violations of each division are multiplied
+// by its weight, overlaps between divisions by the highest weight among the divisions involved.
+func (s *Solver) addHard(b *FitnessBreakdown, ind Individual, in input.InputData) {
+	usage := slotUsageOf(ind.Timetables)
+	v := overlapViolations(usage, in)
+	v.TeacherOverlaps, v.ClassroomOverlaps = weightedOverlaps(ind.Timetables, in, usage)
+	w := s.weights()
+	b.addViolations(v, w, 1)
+	for dIdx, div := range in.Divisions {
+		b.addViolations(s.divisionViolations(ind, in, dIdx), w, divisionWeight(div))
+	}
+}
+
+// addSoft adds the soft constraint penalties.
+func (s *Solver) addSoft(b *FitnessBreakdown, ind Individual, in input.InputData) {
+	// Unbalanced day distribution within a division scaled by its weight, or a week that isn't front-loaded
+	if s.weights().FrontLoad > 0 {
+		b.FrontLoad += s.frontLoadPenalty(ind)
+	} else {
+		b.Imbalance += s.imbalancePenalty(ind, in)
+	}
+
+	// Subjects placed away from the edges or the center of the day
+	b.Placement += s.placementPenalty(ind, in)
+
+	// Too many distinct subjects in a division's day
+	b.SubjectsPerDay += s.subjectsPerDayPenalty(ind)
+
+	// Subjects that should be taught at the same time each day
+	b.TimeBand += s.timeBandPenalty(ind, in)
+
+	// Gaps in timetables of teachers
+	b.TeacherGaps += s.teacherGapsPenalty(ind)
+
+	// Teachers working on too few or too many days
+	b.WorkDays += s.workDaysPenalty(ind, in)
+
+	// Subjects taught in more than one chunk on the same day
+	b.RepeatedDays += s.repeatedDayPenalty(ind, in)
+
+	// Divisions starting their day late, scaled by their weight
+	b.LateStart += s.lateStartPenalty(ind, in)
+
+	// Subjects taught for too many hours in a row
+	b.ConsecutiveHours += s.consecutiveHoursPenalty(ind, in)
+
+	// Lessons outside their preferred classrooms
+	b.PreferredClassrooms += s.preferredClassroomPenalty(ind, in)
+
+	// Teachers working more hours than they may
+	b.TeacherHours += s.teacherHoursPenalty(ind, in)
+
+	// Days with only a few lessons instead of full or free days
+	b.ShortDays += s.shortDayPenalty(ind)
+
+	// Teachers teaching divisions they'd rather not
+	b.PreferredDivisions += s.preferredDivisionPenalty(ind, in)
+
+	// Chunks placed on days their subjects would rather avoid
+	b.PreferredDays += s.preferredDayPenalty(ind, in)
+
+	// Days booked solid through the lunch window, unless that's a hard constraint, scaled by
+	// the weight of the division like the hard one
+	if !s.LunchBreakHard {
+		w := s.weights()
+		for dIdx, div := range in.Divisions {
+			b.LunchBreaks += s.missedLunches(ind.Timetables[dIdx]) * w.LunchBreak * divisionWeight(div)
+		}
+	}
+
+	// Teachers hurrying between buildings from one slot to the next
+	b.BuildingChanges += s.buildingChangePenalty(ind, in)
+
+	// Difficult subjects taught late in the day
+	b.LateSubjects += s.lateSubjectPenalty(ind, in)
+
+	// Subject pairs taught apart or in the wrong order
+	b.SubjectPairs += s.subjectPairPenalty(ind, in)
+}
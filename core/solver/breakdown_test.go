@@ -0,0 +1,51 @@
+// core/solver/breakdown_test.go
+package solver
+
+import (
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+func TestEvaluate(t *testing.T) {
+	a, b, c := input.GlobalSubject("a"), input.GlobalSubject("b"), input.GlobalSubject("c")
+	lj, ak := input.Teacher("LJ"), input.Teacher("AK")
+	r12 := input.Classroom{Name: "12"}
+	none := input.SubjectsGroupNone
+	subject := func(subj *input.GlobalSubject, teacher *input.Teacher) input.Subject {
+		return input.Subject{GlobalSubject: subj, Teacher: teacher, Group: none, Classrooms: []*input.Classroom{&r12}, Allocation: input.Allocation{1}}
+	}
+	lesson := func(subj *input.GlobalSubject, teacher *input.Teacher) output.Day {
+		return output.Day{{{GlobalSubject: subj, Teacher: teacher, Group: &none, Classroom: &r12}}}
+	}
+	in := input.InputData{Divisions: []input.Division{
+		{Name: "A", Subjects: []input.Subject{subject(&a, &lj), subject(&b, &ak)}},
+		{Name: "B", Subjects: []input.Subject{subject(&c, &lj)}},
+	}}
+
+	// Both divisions have LJ in classroom 12 on Monday's first slot, the hour of b isn't placed
+	ind := Individual{Timetables: []output.Days{
+		{lesson(&a, &lj), nil, nil, nil, nil},
+		{lesson(&c, &lj), nil, nil, nil, nil},
+	}}
+	s := &Solver{}
+	w := DefaultFitnessWeights
+	want := FitnessBreakdown{
+		TeacherOverlaps:   w.TeacherOverlap,
+		ClassroomOverlaps: w.ClassroomOverlap,
+		UnmetAllocation:   w.UnmetHour,
+		// The squared deviations of a single Monday lesson from the mean of 0.2 a day add up to 0.8 in each division
+		Imbalance: 2 * 4 * w.Imbalance / 5,
+	}
+	got := s.Evaluate(ind, in)
+	if got != want {
+		t.Errorf("breakdown = %+v, want %+v", got, want)
+	}
+	if f := s.fitness(ind, in); f != got.Total() {
+		t.Errorf("fitness = %d, want the breakdown total %d", f, got.Total())
+	}
+	if got.Hard() != w.TeacherOverlap+w.ClassroomOverlap+w.UnmetHour || got.Soft() != want.Imbalance {
+		t.Errorf("hard = %d, soft = %d, want the hard and soft terms apart", got.Hard(), got.Soft())
+	}
+}
@@ -0,0 +1,53 @@
+// core/solver/cache.go
+package solver
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// Scores of the individuals of the previous generation keyed by hashIndividual, so the
+// ones carried over unchanged (the elite and the surviving half) aren't scored again
+type fitnessCache map[uint64]fitInd
+
+// hashIndividual hashes the placed subjects of every slot of an individual with FNV-1a,
+// individuals with equal timetables have equal hashes regardless of their pointers.
+func hashIndividual(ind Individual) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	writeInt := func(n int) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(n))
+		h.Write(buf[:])
+	}
+	writeString := func(s string) {
+		writeInt(len(s))
+		h.Write([]byte(s))
+	}
+
+	writeInt(len(ind.Timetables))
+	for _, days := range ind.Timetables {
+		writeInt(len(days))
+		for _, day := range days {
+			writeInt(len(day))
+			for _, sg := range day {
+				// Groups differ in length, empty subjects are skipped so padding doesn't matter
+				writeInt(-1)
+				for _, subj := range sg {
+					if subj.GlobalSubject == nil {
+						continue
+					}
+					writeString(string(*subj.GlobalSubject))
+					writeString(string(deref(subj.Teacher)))
+					writeString(deref(subj.Classroom).Name)
+					writeString(string(deref(subj.Group)))
+					writeInt(int(subj.Block))
+					writeInt(len(subj.ExtraClassrooms))
+					for _, c := range subj.ExtraClassrooms {
+						writeString(deref(c).Name)
+					}
+				}
+			}
+		}
+	}
+	return h.Sum64()
+}
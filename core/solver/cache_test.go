@@ -0,0 +1,61 @@
+// core/solver/cache_test.go
+package solver
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestFitnessCache(t *testing.T) {
+	in := input.ExampleInputData
+	s := &Solver{PopulationSize: 30, MoveMutationRate: 0.3}
+	rng := rand.New(rand.NewSource(1))
+	pop := s.initializePopulation(rng, in)
+	_, cache := s.evaluate(pop, in, nil)
+
+	// The next generation keeps half of the individuals and mutates the rest
+	next := slices.Clone(pop)
+	for i := len(next) / 2; i < len(next); i++ {
+		next[i] = next[i].Clone()
+		s.mutate(rng, &next[i], in, 1)
+	}
+
+	cached, _ := s.evaluate(next, in, cache)
+	for i := range len(next) / 2 {
+		if _, ok := cache[hashIndividual(next[i])]; !ok {
+			t.Errorf("kept individual %d isn't in the cache", i)
+		}
+	}
+	fresh, _ := s.evaluate(next, in, nil)
+	for i := range next {
+		if cached[i].fitness != fresh[i].fitness || !slices.Equal(cached[i].scores, fresh[i].scores) {
+			t.Errorf("individual %d: cached score %v, fresh %v", i, cached[i].scores, fresh[i].scores)
+		}
+	}
+}
+
+// BenchmarkFitnessCache evaluates a generation that keeps half of the previous one, with
+// and without the cache of the previous generation.
+func BenchmarkFitnessCache(b *testing.B) {
+	in := input.ExampleInputData
+	s := &Solver{PopulationSize: 50}
+	rng := rand.New(rand.NewSource(1))
+	pop := s.initializePopulation(rng, in)
+	_, cache := s.evaluate(pop, in, nil)
+	next := slices.Clone(pop)
+	copy(next[len(next)/2:], s.initializePopulation(rng, in))
+
+	for _, bm := range []struct {
+		name  string
+		cache fitnessCache
+	}{{"uncached", nil}, {"cached", cache}} {
+		b.Run(bm.name, func(b *testing.B) {
+			for range b.N {
+				s.evaluate(next, in, bm.cache)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// core/solver/checkpoint.go
+package solver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+// A snapshot of a running solve, which can be continued later with ResumeFrom, the solver
+// that resumes it must be configured the same way as the one that produced it
+type Checkpoint struct {
+	Generation  int          `json:"generation"` // The generation that is run next
+	Seed        int64        `json:"seed"`
+	Draws       uint64       `json:"draws"` // The number of values drawn from the generator seeded with Seed
+	Population  []Individual `json:"population"`
+	Best        Individual   `json:"best"`
+	BestFitness int          `json:"best_fitness"`
+	BestScores  []int        `json:"best_scores"`
+	Stale       int          `json:"stale"` // The number of generations since the best individual last improved
+	Rate        float64      `json:"rate"`  // The current mutation rate
+}
+
+// Write encodes the checkpoint as JSON.
+func (cp Checkpoint) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(cp)
+}
+
+func (st *solveState) checkpoint() Checkpoint {
+	return Checkpoint{
+		Generation:  st.generation,
+		Seed:        st.seed,
+		Draws:       st.src.draws,
+		Population:  st.pop,
+		Best:        st.best.ind,
+		BestFitness: st.best.fitness,
+		BestScores:  st.best.scores,
+		Stale:       st.stale,
+		Rate:        st.rate,
+	}
+}
+
+// ResumeFrom continues a solve from a checkpoint written with Checkpoint.Write,
+// following the same trajectory as if the solve was never interrupted.
+func (s *Solver) ResumeFrom(r io.Reader, in input.InputData) (output.OutputData, error) {
+	if err := s.checkPopulation(); err != nil {
+		return output.OutputData{}, err
+	}
+	var cp Checkpoint
+	if err := json.NewDecoder(r).Decode(&cp); err != nil {
+		return output.OutputData{}, fmt.Errorf("error decoding checkpoint: %w", err)
+	}
+	if len(cp.Population) != s.PopulationSize {
+		return output.OutputData{}, fmt.Errorf("checkpoint has a population of %d, but the solver expects %d",
+			len(cp.Population), s.PopulationSize)
+	}
+
+	src := newCountingSource(cp.Seed, cp.Draws)
+	st := &solveState{
+		generation: cp.Generation,
+		seed:       cp.Seed,
+		src:        src,
+		rng:        rand.New(src),
+		pop:        cp.Population,
+		best:       fitInd{ind: cp.Best, fitness: cp.BestFitness, scores: cp.BestScores},
+		stale:      cp.Stale,
+		rate:       cp.Rate,
+	}
+	if st.rate == 0 {
+		st.rate = s.initialMutationRate() // Checkpoints written before the rate was saved
+	}
+	s.run(context.Background(), st, in)
+	if s.Refine {
+		st.best = s.refine(st.rng, st.best, in)
+	}
+	return st.result().Output, nil
+}
+
+// countingSource counts the values drawn from a seeded source, so the state of
+// the generator can be saved as the seed and the number of draws
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+// newCountingSource returns a source seeded with seed that has already drawn draws values.
+func newCountingSource(seed int64, draws uint64) *countingSource {
+	c := &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+	for c.draws < draws {
+		c.Uint64()
+	}
+	return c
+}
+
+func (c *countingSource) Int63() int64 {
+	c.draws++
+	return c.src.Int63()
+}
+
+func (c *countingSource) Uint64() uint64 {
+	c.draws++
+	return c.src.Uint64()
+}
+
+func (c *countingSource) Seed(seed int64) {
+	c.src.Seed(seed)
+	c.draws = 0
+}
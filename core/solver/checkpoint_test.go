@@ -0,0 +1,53 @@
+// core/solver/checkpoint_test.go
+package solver
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestResumeFrom(t *testing.T) {
+	in := input.ExampleInputData
+	s := Solver{PopulationSize: 20, Generations: 60, MutationRate: 0.5, Seed: 7, CheckpointInterval: 20, MaxSubjectsPerDay: 2}
+	var full []Checkpoint
+	s.OnCheckpoint = func(cp Checkpoint) { full = append(full, cp) }
+	want := s.Solve(in)
+	if len(full) != 2 {
+		t.Fatalf("%d checkpoints were written, want 2", len(full))
+	}
+
+	// Resume from the first checkpoint, written and read back as JSON
+	var buf bytes.Buffer
+	if err := full[0].Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var resumed []Checkpoint
+	s.OnCheckpoint = func(cp Checkpoint) { resumed = append(resumed, cp) }
+	got, err := s.ResumeFrom(&buf, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The resumed solve writes the checkpoint it started from again, then follows the same trajectory
+	if len(resumed) != 2 {
+		t.Fatalf("%d checkpoints were written after resuming, want 2", len(resumed))
+	}
+	wantCP, _ := json.Marshal(full[1])
+	gotCP, _ := json.Marshal(resumed[1])
+	if !bytes.Equal(gotCP, wantCP) {
+		t.Error("the checkpoint after resuming differs from the uninterrupted solve")
+	}
+	wantOut, _ := json.Marshal(want)
+	gotOut, _ := json.Marshal(got)
+	if !bytes.Equal(gotOut, wantOut) {
+		t.Errorf("resumed fitness %d, want %d of the uninterrupted solve", got.Fitness, want.Fitness)
+	}
+
+	s.PopulationSize = 10
+	if _, err := s.ResumeFrom(bytes.NewReader(wantCP), in); err == nil {
+		t.Error("resuming with another population size succeeded, want an error")
+	}
+}
@@ -0,0 +1,171 @@
+// core/solver/config.go
+package solver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// The tunable parameters of a solver, as read from a JSON config file, zero values
+// mean the same as the corresponding fields of Solver
+type Config struct {
+	PopulationSize          int           `json:"population_size"`
+	Generations             int           `json:"generations"`
+	MutationRate            float64       `json:"mutation_rate"`
+	MutationRateMin         float64       `json:"mutation_rate_min,omitempty"`
+	MutationRateMax         float64       `json:"mutation_rate_max,omitempty"`
+	MoveMutationRate        float64       `json:"move_mutation_rate,omitempty"`
+	MaxSubjectsPerDay       int           `json:"max_subjects_per_day,omitempty"`
+	Seed                    int64         `json:"seed,omitempty"`
+	SeedPerGeneration       bool          `json:"seed_per_generation,omitempty"`
+	ImbalanceMode           ImbalanceMode `json:"imbalance_mode,omitempty"`
+	MaxParallelism          int           `json:"max_parallelism,omitempty"`
+	MaxSlotsPerDay          int           `json:"max_slots_per_day,omitempty"`
+	DaysPerWeek             int           `json:"days_per_week,omitempty"`
+	DeterministicClassrooms bool          `json:"deterministic_classrooms,omitempty"`
+	Elitism                 int           `json:"elitism,omitempty"`
+	StagnationLimit         int           `json:"stagnation_limit,omitempty"`
+	Refine                  bool          `json:"refine,omitempty"`
+	RefineIterations        int           `json:"refine_iterations,omitempty"`
+	Islands                 int           `json:"islands,omitempty"`
+	MigrationInterval       int           `json:"migration_interval,omitempty"`
+	LunchWindowStart        int           `json:"lunch_window_start,omitempty"`
+	LunchWindowEnd          int           `json:"lunch_window_end,omitempty"`
+	LunchBreakHard          bool          `json:"lunch_break_hard,omitempty"`
+	// Weights missing from the file keep their defaults, nil means DefaultFitnessWeights
+	Weights *FitnessWeights `json:"weights,omitempty"`
+}
+
+// The parameters used when no config file is given
+var DefaultConfig = Config{
+	PopulationSize: 50,
+	Generations:    1000,
+	MutationRate:   0.1,
+}
+
+// LoadConfig reads a config from a JSON file, parameters missing from the file keep
+// their values from DefaultConfig.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	// The weights are decoded over a copy of the defaults, so a weight of 0 in the file disables its term
+	cfg := DefaultConfig
+	weights := DefaultFitnessWeights
+	if cfg.Weights != nil {
+		weights = *cfg.Weights
+	}
+	cfg.Weights = &weights
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks that the parameters are within their ranges, it returns an error
+// naming every parameter that isn't.
+func (cfg Config) Validate() error {
+	var errs []error
+	if cfg.PopulationSize < minPopulationSize {
+		errs = append(errs, fmt.Errorf("population_size must be at least %d, got %d", minPopulationSize, cfg.PopulationSize))
+	}
+	if cfg.Generations < 1 {
+		errs = append(errs, fmt.Errorf("generations must be at least 1, got %d", cfg.Generations))
+	}
+	for _, p := range []struct {
+		name string
+		v    float64
+	}{
+		{"mutation_rate", cfg.MutationRate},
+		{"mutation_rate_min", cfg.MutationRateMin},
+		{"mutation_rate_max", cfg.MutationRateMax},
+		{"move_mutation_rate", cfg.MoveMutationRate},
+	} {
+		if p.v < 0 || p.v > 1 {
+			errs = append(errs, fmt.Errorf("%s must be between 0 and 1, got %g", p.name, p.v))
+		}
+	}
+	if cfg.MutationRateMin > cfg.MutationRateMax {
+		errs = append(errs, fmt.Errorf("mutation_rate_min must not exceed mutation_rate_max, got %g > %g",
+			cfg.MutationRateMin, cfg.MutationRateMax))
+	}
+	switch cfg.ImbalanceMode {
+	case "", ImbalanceSquared, ImbalanceThreshold:
+	default:
+		errs = append(errs, fmt.Errorf("imbalance_mode must be %q or %q, got %q",
+			ImbalanceSquared, ImbalanceThreshold, cfg.ImbalanceMode))
+	}
+	for _, p := range []struct {
+		name string
+		v    int
+	}{
+		{"max_subjects_per_day", cfg.MaxSubjectsPerDay},
+		{"max_parallelism", cfg.MaxParallelism},
+		{"max_slots_per_day", cfg.MaxSlotsPerDay},
+		{"days_per_week", cfg.DaysPerWeek},
+		{"elitism", cfg.Elitism},
+		{"stagnation_limit", cfg.StagnationLimit},
+		{"refine_iterations", cfg.RefineIterations},
+		{"islands", cfg.Islands},
+		{"migration_interval", cfg.MigrationInterval},
+		{"lunch_window_start", cfg.LunchWindowStart},
+		{"lunch_window_end", cfg.LunchWindowEnd},
+	} {
+		if p.v < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", p.name, p.v))
+		}
+	}
+	if cfg.Weights != nil && cfg.Weights.hasNegative() {
+		errs = append(errs, errors.New("weights must not be negative"))
+	}
+	if cfg.LunchWindowEnd > 0 && cfg.LunchWindowEnd < cfg.LunchWindowStart {
+		errs = append(errs, fmt.Errorf("lunch_window_end must not be before lunch_window_start, got %d < %d",
+			cfg.LunchWindowEnd, cfg.LunchWindowStart))
+	}
+	if cfg.Elitism > cfg.PopulationSize {
+		errs = append(errs, fmt.Errorf("elitism must not exceed population_size, got %d", cfg.Elitism))
+	}
+	return errors.Join(errs...)
+}
+
+// New returns a solver configured with cfg, or an error if the config isn't valid.
+func New(cfg Config) (*Solver, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid solver config: %w", err)
+	}
+	var weights *FitnessWeights
+	if cfg.Weights != nil {
+		w := *cfg.Weights
+		weights = &w
+	}
+	return &Solver{
+		PopulationSize:          cfg.PopulationSize,
+		Generations:             cfg.Generations,
+		MutationRate:            cfg.MutationRate,
+		MutationRateMin:         cfg.MutationRateMin,
+		MutationRateMax:         cfg.MutationRateMax,
+		MoveMutationRate:        cfg.MoveMutationRate,
+		MaxSubjectsPerDay:       cfg.MaxSubjectsPerDay,
+		Seed:                    cfg.Seed,
+		SeedPerGeneration:       cfg.SeedPerGeneration,
+		ImbalanceMode:           cfg.ImbalanceMode,
+		MaxParallelism:          cfg.MaxParallelism,
+		MaxSlotsPerDay:          cfg.MaxSlotsPerDay,
+		DaysPerWeek:             cfg.DaysPerWeek,
+		DeterministicClassrooms: cfg.DeterministicClassrooms,
+		Elitism:                 cfg.Elitism,
+		StagnationLimit:         cfg.StagnationLimit,
+		Refine:                  cfg.Refine,
+		RefineIterations:        cfg.RefineIterations,
+		Islands:                 cfg.Islands,
+		MigrationInterval:       cfg.MigrationInterval,
+		LunchWindowStart:        cfg.LunchWindowStart,
+		LunchWindowEnd:          cfg.LunchWindowEnd,
+		LunchBreakHard:          cfg.LunchBreakHard,
+		Weights:                 weights,
+	}, nil
+}
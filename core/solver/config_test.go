@@ -0,0 +1,108 @@
+// core/solver/config_test.go
+package solver
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		field  string // The field the error has to name, empty when the config is valid
+	}{
+		{"default", func(*Config) {}, ""},
+		{"smallest population", func(c *Config) { c.PopulationSize = 2 }, ""},
+		{"no mutation", func(c *Config) { c.MutationRate = 0 }, ""},
+		{"full mutation", func(c *Config) { c.MutationRate = 1 }, ""},
+		{"one generation", func(c *Config) { c.Generations = 1 }, ""},
+		{"population of one", func(c *Config) { c.PopulationSize = 1 }, "population_size"},
+		{"negative mutation", func(c *Config) { c.MutationRate = -0.1 }, "mutation_rate"},
+		{"mutation above one", func(c *Config) { c.MutationRate = 1.5 }, "mutation_rate"},
+		{"no generations", func(c *Config) { c.Generations = 0 }, "generations"},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig
+		tt.modify(&cfg)
+
+		err := cfg.Validate()
+		switch {
+		case tt.field == "" && err != nil:
+			t.Errorf("%s: Validate() = %v, want no error", tt.name, err)
+		case tt.field != "" && (err == nil || !strings.Contains(err.Error(), tt.field)):
+			t.Errorf("%s: Validate() = %v, want an error naming %s", tt.name, err, tt.field)
+		}
+
+		s, err := New(cfg)
+		if (err == nil) != (tt.field == "") {
+			t.Errorf("%s: New() error = %v, want an error: %v", tt.name, err, tt.field != "")
+		}
+		if err == nil && (s.PopulationSize != cfg.PopulationSize || s.MutationRate != cfg.MutationRate) {
+			t.Errorf("%s: New() = %+v, doesn't carry the config", tt.name, s)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadConfig(write("partial.json", `{"population_size": 10}`))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.PopulationSize != 10 || cfg.Generations != DefaultConfig.Generations {
+		t.Errorf("LoadConfig() = %+v, want population 10 and the default generations", cfg)
+	}
+
+	if _, err := LoadConfig(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadConfig() of a nonexistent path returned no error")
+	}
+	if _, err := LoadConfig(write("invalid.json", `{"population_size": `)); err == nil {
+		t.Error("LoadConfig() of invalid JSON returned no error")
+	}
+}
+
+func TestLoadConfigWeights(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"generations": 10, "weights": {"imbalance": 0, "front_load": 10}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := cfg.Weights
+	if w.Imbalance != 0 || w.FrontLoad != 10 || w.TeacherOverlap != DefaultFitnessWeights.TeacherOverlap {
+		t.Errorf("weights = %+v, want imbalance disabled, front-loading 10 and the rest defaults", *w)
+	}
+	if DefaultFitnessWeights.Imbalance == 0 {
+		t.Error("loading a config changed the default weights")
+	}
+
+	// The solver gets its own copy of the weights
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.FrontLoad = 20
+	if s.Weights.FrontLoad != 10 {
+		t.Errorf("front-load weight of the solver = %d after changing the config, want 10", s.Weights.FrontLoad)
+	}
+
+	cfg.Weights.TeacherGap = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("negative weight isn't rejected")
+	}
+}
@@ -0,0 +1,641 @@
+// core/solver/constraints.go
+package solver
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+// The share of classroom picks made outside a subject's preferred classrooms
+const offPreferredClassroomRate = 0.2
+
+// placedAs reports whether a placed subject was placed for the given input subject,
+// subjects are compared by value, so timetables decoded from JSON still match the input.
+func placedAs(placed output.Subject, subj input.Subject) bool {
+	return equalValues(placed.GlobalSubject, subj.GlobalSubject) && equalValues(placed.Teacher, subj.Teacher)
+}
+
+func equalValues[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// deref returns the value of the pointer, or the zero value for nil.
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+// subjectsPerDayPenalty penalizes division days that fragment learning
+// into more distinct subjects than Solver.MaxSubjectsPerDay allows.
+func (s *Solver) subjectsPerDayPenalty(ind Individual) int {
+	w := s.weights()
+	if s.MaxSubjectsPerDay <= 0 {
+		return 0
+	}
+
+	score := 0
+	for _, divTT := range ind.Timetables {
+		for day := range divTT {
+			distinct := make(map[input.GlobalSubject]bool)
+			for _, sg := range divTT[day] {
+				for _, subj := range sg {
+					if subj.GlobalSubject != nil {
+						distinct[*subj.GlobalSubject] = true
+					}
+				}
+			}
+			if excess := len(distinct) - s.MaxSubjectsPerDay; excess > 0 {
+				score += excess * w.SubjectsPerDay
+			}
+		}
+	}
+	return score
+}
+
+// timeBandPenalty penalizes subjects marked with SameTimeOfDay whose blocks
+// start at different slots on different days.
+func (s *Solver) timeBandPenalty(ind Individual, in input.InputData) int {
+	weight := s.weights().TimeBand
+
+	score := 0
+	for dIdx, div := range in.Divisions {
+		for _, subj := range div.Subjects {
+			if !subj.SameTimeOfDay {
+				continue
+			}
+
+			minSlot, maxSlot := -1, -1
+			for day := range ind.Timetables[dIdx] {
+				start := firstSlotOf(ind.Timetables[dIdx][day], subj)
+				if start < 0 {
+					continue
+				}
+				if minSlot < 0 || start < minSlot {
+					minSlot = start
+				}
+				if start > maxSlot {
+					maxSlot = start
+				}
+			}
+			score += (maxSlot - minSlot) * weight
+		}
+	}
+	return score
+}
+
+// firstSlotOf returns the first slot of the day in which the subject is placed, or -1.
+func firstSlotOf(day output.Day, subj input.Subject) int {
+	for slot, sg := range day {
+		for _, placed := range sg {
+			if placed.GlobalSubject != nil && placedAs(placed, subj) {
+				return slot
+			}
+		}
+	}
+	return -1
+}
+
+// teacherSchedule projects the timetables of all divisions onto the teachers,
+// returning the sorted slots each teacher is occupied in, indexed by the day.
+func teacherSchedule(ind Individual) map[input.Teacher][][]int {
+	weekDays := 0
+	for _, divTT := range ind.Timetables {
+		weekDays = max(weekDays, len(divTT))
+	}
+
+	schedule := make(map[input.Teacher][][]int)
+	for _, divTT := range ind.Timetables {
+		for day := range divTT {
+			for slot, sg := range divTT[day] {
+				for _, subj := range sg {
+					if subj.GlobalSubject == nil || subj.Teacher == nil {
+						continue
+					}
+					days := schedule[*subj.Teacher]
+					if days == nil {
+						days = make([][]int, weekDays)
+						schedule[*subj.Teacher] = days
+					}
+					days[day] = append(days[day], slot)
+				}
+			}
+		}
+	}
+
+	for _, days := range schedule {
+		for day := range days {
+			sort.Ints(days[day])
+		}
+	}
+	return schedule
+}
+
+// workDaysPenalty penalizes teachers whose lessons span fewer or more distinct days than allowed.
+func (s *Solver) workDaysPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	if len(in.TeacherConstraints) == 0 {
+		return 0
+	}
+
+	score := 0
+	for teacher, days := range teacherSchedule(ind) {
+		c, ok := in.TeacherConstraints[teacher]
+		if !ok {
+			continue
+		}
+
+		workDays := 0
+		for _, slots := range days {
+			if len(slots) > 0 {
+				workDays++
+			}
+		}
+		if c.MinWorkDays > 0 && workDays < int(c.MinWorkDays) {
+			score += (int(c.MinWorkDays) - workDays) * w.WorkDay
+		}
+		if c.MaxWorkDays > 0 && workDays > int(c.MaxWorkDays) {
+			score += (workDays - int(c.MaxWorkDays)) * w.WorkDay
+		}
+	}
+	return score
+}
+
+// imbalancePenalty penalizes divisions whose daily loads (number of slots with lessons per day) are
+// unbalanced, heavier divisions pay more, so they get the more balanced weeks, free slots padding a
+// day don't count towards its load.
+func (s *Solver) imbalancePenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	score := 0
+	for dIdx, div := range in.Divisions {
+		if dIdx >= len(ind.Timetables) {
+			break
+		}
+		weight := w.Imbalance * divisionWeight(div)
+		dayCounts := make([]int, len(ind.Timetables[dIdx]))
+		for day := range dayCounts {
+			dayCounts[day] = lessonCount(ind.Timetables[dIdx][day])
+		}
+		if len(dayCounts) == 0 {
+			continue
+		}
+
+		if s.ImbalanceMode == ImbalanceThreshold {
+			minC, maxC := slices.Min(dayCounts), slices.Max(dayCounts)
+			if maxC-minC > 4 {
+				score += (maxC - minC) * weight
+			}
+			continue
+		}
+
+		total := 0
+		for _, c := range dayCounts {
+			total += c
+		}
+		mean := float64(total) / float64(len(dayCounts))
+		deviation := 0.0
+		for _, c := range dayCounts {
+			deviation += (float64(c) - mean) * (float64(c) - mean)
+		}
+		score += int(math.Round(deviation * float64(weight)))
+	}
+	return score
+}
+
+// shortDayPenalty penalizes the days of a division that have lessons but fewer groups than
+// half of its mean daily load, per missing group, so the week tends towards full days or
+// free days instead of days with a straggling lesson or two. The mean is used rather than
+// the median since short days are often the majority, e.g. in 1, 1, 1, 6, 6.
+func (s *Solver) shortDayPenalty(ind Individual) int {
+	w := s.weights()
+	score := 0
+	for _, days := range ind.Timetables {
+		if len(days) == 0 {
+			continue
+		}
+		total := 0
+		for _, day := range days {
+			total += lessonCount(day)
+		}
+		// Half of the mean, rounded up
+		threshold := (total + 2*len(days) - 1) / (2 * len(days))
+		for _, day := range days {
+			if n := lessonCount(day); n > 0 && n < threshold {
+				score += (threshold - n) * w.ShortDay
+			}
+		}
+	}
+	return score
+}
+
+// frontLoadPenalty penalizes divisions whose daily load increases from one day to the next.
+func (s *Solver) frontLoadPenalty(ind Individual) int {
+	weight := s.weights().FrontLoad
+	score := 0
+	for _, divTT := range ind.Timetables {
+		for day := 1; day < len(divTT); day++ {
+			if increase := lessonCount(divTT[day]) - lessonCount(divTT[day-1]); increase > 0 {
+				score += increase * weight
+			}
+		}
+	}
+	return score
+}
+
+// placementPenalty penalizes subjects placed away from the region of the day requested
+// by their placement, proportionally to the distance, so the algorithm can gradually
+// move them towards it.
+func (s *Solver) placementPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	score := 0
+	for dIdx, div := range in.Divisions {
+		for _, d := range ind.Timetables[dIdx] {
+			for slot, sg := range d {
+				for _, placed := range sg {
+					if placed.GlobalSubject == nil {
+						continue
+					}
+					for _, subj := range div.Subjects {
+						if placedAs(placed, subj) {
+							score += placementDistance(subj.Placement, slot, len(d)) * w.Placement
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return score
+}
+
+// teacherGapsPenalty penalizes empty slots between the first and the last lesson of a teacher's day.
+func (s *Solver) teacherGapsPenalty(ind Individual) int {
+	w := s.weights()
+	score := 0
+	for _, days := range teacherSchedule(ind) {
+		for _, slots := range days {
+			if len(slots) < 2 {
+				continue
+			}
+			occupied := len(slices.Compact(slices.Clone(slots)))
+			span := slots[len(slots)-1] - slots[0] + 1
+			score += (span - occupied) * w.TeacherGap
+		}
+	}
+	return score
+}
+
+// repeatedDayPenalty penalizes subjects whose allocation spans multiple chunks when
+// more than one of them lands on the same day, hours of a multi-hour block share
+// their Block and count as a single chunk.
+func (s *Solver) repeatedDayPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	score := 0
+	for dIdx, div := range in.Divisions {
+		if dIdx >= len(ind.Timetables) {
+			break
+		}
+		for _, subj := range div.Subjects {
+			for _, day := range s.freeDays() {
+				// The timetables may be shorter than the configured week, e.g. when checking a published one
+				if day >= len(ind.Timetables[dIdx]) {
+					continue
+				}
+				blocks := make(map[uint]bool)
+				chunks := 0
+				for _, sg := range ind.Timetables[dIdx][day] {
+					for _, placed := range sg {
+						if placed.GlobalSubject == nil || !placedAs(placed, subj) || !equalValues(placed.Group, &subj.Group) {
+							continue
+						}
+						if placed.Block == 0 {
+							chunks++
+						} else if !blocks[placed.Block] {
+							blocks[placed.Block] = true
+							chunks++
+						}
+					}
+				}
+				if chunks > 1 {
+					score += (chunks - 1) * w.RepeatedDay
+				}
+			}
+		}
+	}
+	return score
+}
+
+// divisionWeight returns the multiplier of a division's penalties, 0 counts as 1.
+func divisionWeight(div input.Division) int {
+	return max(int(div.Weight), 1)
+}
+
+// lateStartPenalty penalizes the empty slots a division's days start with after its
+// earliest start, heavier divisions pay more, so they get the early slots.
+func (s *Solver) lateStartPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	score := 0
+	for dIdx, div := range in.Divisions {
+		for _, day := range ind.Timetables[dIdx] {
+			late := 0
+			for slot := int(div.EarliestStart); slot < len(day) && day[slot].IsEmpty(); slot++ {
+				late++
+			}
+			// A day without lessons doesn't start late
+			if late < len(day)-int(div.EarliestStart) {
+				score += late * divisionWeight(div) * w.LateStart
+			}
+		}
+	}
+	return score
+}
+
+// consecutiveHoursPenalty penalizes every hour a division spends on the same subject past
+// its MaxConsecutive in a row, with several caps for a subject the lowest one applies.
+func (s *Solver) consecutiveHoursPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	score := 0
+	for dIdx, div := range in.Divisions {
+		caps := make(map[input.GlobalSubject]int)
+		for _, subj := range div.Subjects {
+			if subj.MaxConsecutive == 0 || subj.GlobalSubject == nil {
+				continue
+			}
+			if c, ok := caps[*subj.GlobalSubject]; !ok || int(subj.MaxConsecutive) < c {
+				caps[*subj.GlobalSubject] = int(subj.MaxConsecutive)
+			}
+		}
+
+		for subject, limit := range caps {
+			for _, day := range ind.Timetables[dIdx] {
+				run := 0
+				for _, sg := range day {
+					if slices.ContainsFunc(sg, func(placed output.Subject) bool {
+						return placed.GlobalSubject != nil && *placed.GlobalSubject == subject
+					}) {
+						run++
+						if run > limit {
+							score += w.ConsecutiveHour
+						}
+					} else {
+						run = 0
+					}
+				}
+			}
+		}
+	}
+	return score
+}
+
+// hasClassroom reports whether the classroom is one of rooms, compared by name.
+func hasClassroom(rooms []*input.Classroom, c *input.Classroom) bool {
+	return slices.ContainsFunc(rooms, func(r *input.Classroom) bool {
+		return r != nil && c != nil && r.Name == c.Name
+	})
+}
+
+// preferredClassroomPenalty penalizes lessons placed outside their subject's preferred classrooms.
+func (s *Solver) preferredClassroomPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	score := 0
+	for dIdx, div := range in.Divisions {
+		for _, d := range ind.Timetables[dIdx] {
+			for _, sg := range d {
+				for _, placed := range sg {
+					if placed.GlobalSubject == nil || placed.Classroom == nil {
+						continue
+					}
+					for _, subj := range div.Subjects {
+						if placedAs(placed, subj) {
+							if len(subj.PreferredClassrooms) > 0 && !hasClassroom(subj.PreferredClassrooms, placed.Classroom) {
+								score += w.PreferredClassroom
+							}
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	return score
+}
+
+// preferredDivisionPenalty penalizes every hour a teacher teaches a division that isn't
+// on their PreferredDivisions list, lessons whose teacher is pinned by a subject of the
+// division in the input aren't penalized, since the solver can't move them to another teacher.
+func (s *Solver) preferredDivisionPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	if len(in.TeacherConstraints) == 0 {
+		return 0
+	}
+
+	score := 0
+	for dIdx, div := range in.Divisions {
+		for _, d := range ind.Timetables[dIdx] {
+			for _, sg := range d {
+				for _, placed := range sg {
+					if placed.GlobalSubject == nil || placed.Teacher == nil {
+						continue
+					}
+					pinned := slices.ContainsFunc(div.Subjects, func(subj input.Subject) bool {
+						return placedAs(placed, subj)
+					})
+					if pinned {
+						continue
+					}
+					preferred := in.TeacherConstraints[*placed.Teacher].PreferredDivisions
+					if len(preferred) > 0 && !slices.Contains(preferred, div.Name) {
+						score += w.PreferredDivision
+					}
+				}
+			}
+		}
+	}
+	return score
+}
+
+// preferredDayPenalty penalizes every chunk placed on a day outside its subject's PreferredDays,
+// the hours of a block count as a single chunk.
+func (s *Solver) preferredDayPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	score := 0
+	for dIdx, div := range in.Divisions {
+		for _, subj := range div.Subjects {
+			if len(subj.PreferredDays) == 0 {
+				continue
+			}
+			for day, d := range ind.Timetables[dIdx] {
+				if slices.Contains(subj.PreferredDays, day) {
+					continue
+				}
+				blocks := make(map[uint]bool)
+				for _, sg := range d {
+					for _, placed := range sg {
+						if placed.GlobalSubject == nil || !placedAs(placed, subj) || blocks[placed.Block] {
+							continue
+						}
+						if placed.Block > 0 {
+							blocks[placed.Block] = true
+						}
+						score += w.PreferredDay
+					}
+				}
+			}
+		}
+	}
+	return score
+}
+
+// buildingChangePenalty penalizes every pair of consecutive slots in which a teacher teaches
+// in classrooms of different buildings, across all divisions, the buildings are taken from
+// the top level classrooms.
+func (s *Solver) buildingChangePenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	buildings := make(map[string]string)
+	for _, c := range in.Classrooms {
+		if c.Building != "" {
+			buildings[c.Name] = c.Building
+		}
+	}
+	if len(buildings) == 0 {
+		return 0
+	}
+
+	type slotKey struct {
+		teacher   input.Teacher
+		day, slot int
+	}
+	taughtIn := make(map[slotKey]string)
+	for _, days := range ind.Timetables {
+		for day, d := range days {
+			for slot, sg := range d {
+				for _, placed := range sg {
+					if placed.GlobalSubject == nil || placed.Teacher == nil || placed.Classroom == nil {
+						continue
+					}
+					key := slotKey{*placed.Teacher, day, slot}
+					if building, ok := buildings[placed.Classroom.Name]; ok && taughtIn[key] == "" {
+						taughtIn[key] = building
+					}
+				}
+			}
+		}
+	}
+
+	score := 0
+	for key, building := range taughtIn {
+		next := taughtIn[slotKey{key.teacher, key.day, key.slot + 1}]
+		if next != "" && next != building {
+			score += w.BuildingChange
+		}
+	}
+	return score
+}
+
+// teacherHoursPenalty penalizes every hour a teacher teaches above their MaxHoursPerDay
+// in a day or above their MaxHoursPerWeek in the week, across all divisions, a slot
+// counts once even when the teacher is double booked in it.
+func (s *Solver) teacherHoursPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	if len(in.TeacherConstraints) == 0 {
+		return 0
+	}
+
+	score := 0
+	for teacher, days := range teacherSchedule(ind) {
+		c, ok := in.TeacherConstraints[teacher]
+		if !ok || (c.MaxHoursPerDay == 0 && c.MaxHoursPerWeek == 0) {
+			continue
+		}
+
+		week := 0
+		for _, slots := range days {
+			hours := len(slices.Compact(slices.Clone(slots)))
+			week += hours
+			if c.MaxHoursPerDay > 0 && hours > int(c.MaxHoursPerDay) {
+				score += (hours - int(c.MaxHoursPerDay)) * w.TeacherHour
+			}
+		}
+		if c.MaxHoursPerWeek > 0 && week > int(c.MaxHoursPerWeek) {
+			score += (week - int(c.MaxHoursPerWeek)) * w.TeacherHour
+		}
+	}
+	return score
+}
+
+// lateSubjectPenalty penalizes every hour of a difficult global subject by the number of slots
+// it's taught after its division's earliest start times the subject's difficulty, subjects
+// that prefer the morning without a difficulty count as a difficulty of 1.
+func (s *Solver) lateSubjectPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	if len(in.GlobalSubjectInfo) == 0 {
+		return 0
+	}
+
+	score := 0
+	for dIdx, div := range in.Divisions {
+		for _, d := range ind.Timetables[dIdx] {
+			for slot, sg := range d {
+				late := slot - int(div.EarliestStart)
+				if late <= 0 {
+					continue
+				}
+				for _, placed := range sg {
+					if placed.GlobalSubject == nil {
+						continue
+					}
+					score += late * subjectDifficulty(in.GlobalSubjectInfo[*placed.GlobalSubject]) * w.LateSubject
+				}
+			}
+		}
+	}
+	return score
+}
+
+// subjectDifficulty returns the difficulty of a global subject, at least 1 when it prefers the morning.
+func subjectDifficulty(info input.SubjectInfo) int {
+	if info.PreferMorning {
+		return max(int(info.Difficulty), 1)
+	}
+	return int(info.Difficulty)
+}
+
+// subjectPairPenalty penalizes every run of consecutive lessons of the first subject of a
+// division's pair that isn't immediately followed by the second subject, and every run of the
+// second subject that isn't immediately preceded by the first, so a pair taught on different
+// days, with a gap between or in the wrong order is penalized twice.
+func (s *Solver) subjectPairPenalty(ind Individual, in input.InputData) int {
+	w := s.weights()
+	teaches := func(day output.Day, slot int, subject input.GlobalSubject) bool {
+		return slices.ContainsFunc(day.Slot(slot), func(placed output.Subject) bool {
+			return placed.GlobalSubject != nil && *placed.GlobalSubject == subject
+		})
+	}
+
+	score := 0
+	for dIdx, div := range in.Divisions {
+		for _, pair := range div.SubjectPairs {
+			for _, day := range ind.Timetables[dIdx] {
+				for slot := range day {
+					// The last lesson of a run of the first subject, and the first one of the second
+					if teaches(day, slot, pair.First) && !teaches(day, slot+1, pair.First) && !teaches(day, slot+1, pair.Then) {
+						score += w.SubjectPair
+					}
+					if teaches(day, slot, pair.Then) && !teaches(day, slot-1, pair.Then) && !teaches(day, slot-1, pair.First) {
+						score += w.SubjectPair
+					}
+				}
+			}
+		}
+	}
+	return score
+}
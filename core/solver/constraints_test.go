@@ -0,0 +1,456 @@
+// core/solver/constraints_test.go
+package solver
+
+import (
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+// lessons returns a day with a whole division lesson of each subject in consecutive slots,
+// a nil subject leaves its slot free.
+func lessons(subjects ...*input.GlobalSubject) output.Day {
+	day := make(output.Day, len(subjects))
+	for slot, subj := range subjects {
+		if subj != nil {
+			day[slot] = output.SubjectsGroup{{GlobalSubject: subj}}
+		}
+	}
+	return day
+}
+
+// week returns an individual with a single division taught the given days.
+func week(days ...output.Day) Individual {
+	return Individual{Timetables: []output.Days{days}}
+}
+
+func TestSubjectsPerDayPenalty(t *testing.T) {
+	a, b, c, d := input.GlobalSubject("a"), input.GlobalSubject("b"), input.GlobalSubject("c"), input.GlobalSubject("d")
+	s := &Solver{MaxSubjectsPerDay: 2}
+
+	fragmented := s.subjectsPerDayPenalty(week(lessons(&a, &b, &c, &d)))
+	focused := s.subjectsPerDayPenalty(week(lessons(&a, &a, &b, &b)))
+	if focused != 0 {
+		t.Errorf("focused day penalty = %d, want 0", focused)
+	}
+	if fragmented <= focused {
+		t.Errorf("fragmented day penalty = %d, want more than the focused %d", fragmented, focused)
+	}
+}
+
+func TestTimeBandPenalty(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	in := input.InputData{Divisions: []input.Division{{Subjects: []input.Subject{
+		{GlobalSubject: &a, SameTimeOfDay: true, Allocation: input.Allocation{1, 1, 1}},
+		{GlobalSubject: &b, Allocation: input.Allocation{1, 1, 1}},
+	}}}}
+	s := &Solver{}
+
+	aligned := s.timeBandPenalty(week(lessons(&b, &a), lessons(&b, &a), lessons(&b, &a)), in)
+	scattered := s.timeBandPenalty(week(lessons(&a, &b), lessons(&b, &a), lessons(&b, nil, &a)), in)
+	if aligned != 0 {
+		t.Errorf("same time each day penalty = %d, want 0", aligned)
+	}
+	if scattered <= aligned {
+		t.Errorf("scattered penalty = %d, want more than the aligned %d", scattered, aligned)
+	}
+}
+
+// taught returns a subjects group with a whole division lesson of the subject taught by the teacher.
+func taught(subj *input.GlobalSubject, teacher *input.Teacher) output.SubjectsGroup {
+	return output.SubjectsGroup{{GlobalSubject: subj, Teacher: teacher}}
+}
+
+func TestWorkDaysPenalty(t *testing.T) {
+	a := input.GlobalSubject("a")
+	lj := input.Teacher("LJ")
+	g := taught(&a, &lj)
+	in := input.InputData{TeacherConstraints: map[input.Teacher]input.TeacherConstraints{lj: {MinWorkDays: 2, MaxWorkDays: 3}}}
+	s := &Solver{}
+
+	tests := []struct {
+		name    string
+		ind     Individual
+		penalty bool
+	}{
+		{"concentrated", week(output.Day{g, g, g, g}, nil, nil, nil, nil), true},
+		{"within bounds", week(output.Day{g, g}, output.Day{g, g}, nil, nil, nil), false},
+		{"spread", week(output.Day{g}, output.Day{g}, output.Day{g}, output.Day{g}, nil), true},
+	}
+	for _, tt := range tests {
+		if got := s.workDaysPenalty(tt.ind, in); (got > 0) != tt.penalty {
+			t.Errorf("%s: penalty = %d, want a penalty %v", tt.name, got, tt.penalty)
+		}
+	}
+}
+
+// loads returns an individual with a single division whose days have the given numbers of lessons.
+func loads(counts ...int) Individual {
+	a := input.GlobalSubject("a")
+	days := make(output.Days, len(counts))
+	for day, n := range counts {
+		for range n {
+			days[day] = append(days[day], output.SubjectsGroup{{GlobalSubject: &a}})
+		}
+	}
+	return Individual{Timetables: []output.Days{days}}
+}
+
+func TestImbalancePenalty(t *testing.T) {
+	in := input.InputData{Divisions: []input.Division{{Name: "A"}}}
+	slight, moderate := loads(5, 4, 4, 4, 3), loads(6, 4, 4, 4, 2)
+
+	// Neither spread reaches the threshold, the squared deviations still tell them apart
+	threshold := &Solver{ImbalanceMode: ImbalanceThreshold}
+	if a, b := threshold.imbalancePenalty(slight, in), threshold.imbalancePenalty(moderate, in); a != 0 || b != 0 {
+		t.Errorf("threshold penalties = %d, %d, want 0 below the threshold", a, b)
+	}
+	squared := &Solver{}
+	if a, b := squared.imbalancePenalty(slight, in), squared.imbalancePenalty(moderate, in); a >= b || a == 0 {
+		t.Errorf("squared penalties = %d, %d, want the slight imbalance below the moderate one", a, b)
+	}
+}
+
+func TestFrontLoadPenalty(t *testing.T) {
+	in := input.InputData{Divisions: []input.Division{{Name: "A"}}}
+	front, balanced, back := loads(6, 5, 4, 3, 2), loads(4, 4, 4, 4, 4), loads(2, 3, 4, 5, 6)
+
+	// Without front-loading the balanced week is preferred
+	s := &Solver{}
+	if s.Evaluate(front, in).Total() <= s.Evaluate(balanced, in).Total() {
+		t.Error("front-loaded week isn't worse than the balanced one without front-loading")
+	}
+
+	s = weighted(func(w *FitnessWeights) { w.FrontLoad = 10 })
+	if f, b := s.Evaluate(front, in).Total(), s.Evaluate(balanced, in).Total(); f > b {
+		t.Errorf("front-loaded total = %d, want at most the balanced %d", f, b)
+	}
+	if f, b := s.frontLoadPenalty(front), s.frontLoadPenalty(back); f != 0 || b != 4*10 {
+		t.Errorf("front-load penalties = %d, %d, want 0 for the front-loaded week and 40 for the back-loaded one", f, b)
+	}
+}
+
+func TestTeacherGapsPenalty(t *testing.T) {
+	a := input.GlobalSubject("a")
+	lj := input.Teacher("LJ")
+	g, free := taught(&a, &lj), output.SubjectsGroup{}
+	s := &Solver{}
+
+	apart := s.teacherGapsPenalty(week(output.Day{g, free, free, g}))
+	together := s.teacherGapsPenalty(week(output.Day{g, g}))
+	if together != 0 {
+		t.Errorf("consecutive lessons penalty = %d, want 0", together)
+	}
+	if apart <= together {
+		t.Errorf("slots 0 and 3 penalty = %d, want more than slots 0 and 1 %d", apart, together)
+	}
+}
+
+func TestConsecutiveHoursPenalty(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &a, MaxConsecutive: 2, Allocation: input.Allocation{4}},
+		{GlobalSubject: &b, Allocation: input.Allocation{1}},
+	}}}}
+	s := &Solver{}
+
+	straight := s.consecutiveHoursPenalty(week(lessons(&a, &a, &a, &a, &b)), in)
+	split := s.consecutiveHoursPenalty(week(lessons(&a, &a, &b, &a, &a)), in)
+	if split != 0 {
+		t.Errorf("two plus two with a break penalty = %d, want 0", split)
+	}
+	if straight != 2*DefaultFitnessWeights.ConsecutiveHour {
+		t.Errorf("four in a row penalty = %d, want %d for the 2 hours past the cap", straight, 2*DefaultFitnessWeights.ConsecutiveHour)
+	}
+}
+
+func TestPreferredClassroomPenalty(t *testing.T) {
+	a := input.GlobalSubject("a")
+	lab, hall := &input.Classroom{Name: "lab", Capacity: 30}, &input.Classroom{Name: "hall", Capacity: 30}
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &a, Classrooms: []*input.Classroom{lab, hall}, PreferredClassrooms: []*input.Classroom{lab}, Allocation: input.Allocation{1}},
+	}}}}
+	placedIn := func(room *input.Classroom) Individual {
+		return week(output.Day{{{GlobalSubject: &a, Classroom: room}}}, nil, nil, nil, nil)
+	}
+	s := &Solver{}
+
+	preferred, offList := s.Evaluate(placedIn(lab), in), s.Evaluate(placedIn(hall), in)
+	if preferred.PreferredClassrooms != 0 {
+		t.Errorf("preferred room penalty = %d, want 0", preferred.PreferredClassrooms)
+	}
+	if preferred.Total() >= offList.Total() {
+		t.Errorf("preferred room total = %d, want less than the off-list room's %d", preferred.Total(), offList.Total())
+	}
+}
+
+func TestDuplicateGroups(t *testing.T) {
+	english := input.GlobalSubject("angielski")
+	ak, lj := input.Teacher("AK"), input.Teacher("LJ")
+	one, two := input.SubjectsGroupOne, input.SubjectsGroupTwo
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &english, Teacher: &ak, Group: one, Allocation: input.Allocation{1}},
+		{GlobalSubject: &english, Teacher: &lj, Group: two, Allocation: input.Allocation{1}},
+	}}}}
+	parallel := func(a, b input.SubjectsGroupType) Individual {
+		return week(output.Day{{{GlobalSubject: &english, Teacher: &ak, Group: &a}, {GlobalSubject: &english, Teacher: &lj, Group: &b}}}, nil, nil, nil, nil)
+	}
+	s := &Solver{}
+
+	distinct, doubled := s.Evaluate(parallel(one, two), in), s.Evaluate(parallel(one, one), in)
+	if distinct.DuplicateGroups != 0 {
+		t.Errorf("groups one and two penalty = %d, want 0", distinct.DuplicateGroups)
+	}
+	if want := DefaultFitnessWeights.DuplicateGroup; doubled.DuplicateGroups != want {
+		t.Errorf("group one twice penalty = %d, want %d", doubled.DuplicateGroups, want)
+	}
+}
+
+func TestTeacherHoursPenalty(t *testing.T) {
+	a := input.GlobalSubject("a")
+	lj := input.Teacher("LJ")
+	g, free := taught(&a, &lj), output.SubjectsGroup{}
+	in := input.InputData{TeacherConstraints: map[input.Teacher]input.TeacherConstraints{lj: {MaxHoursPerDay: 4, MaxHoursPerWeek: 6}}}
+	s := &Solver{}
+
+	// Monday's hours are split between two divisions, so they only add up across them
+	monday := func(first, second output.Day) Individual {
+		return Individual{Timetables: []output.Days{{first}, {second}}}
+	}
+	if p := s.teacherHoursPenalty(monday(output.Day{g, g}, output.Day{free, free, g, g}), in); p != 0 {
+		t.Errorf("4 hours with a daily cap of 4 penalty = %d, want 0", p)
+	}
+	if p := s.teacherHoursPenalty(monday(output.Day{g, g, g}, output.Day{free, free, free, g, g}), in); p != DefaultFitnessWeights.TeacherHour {
+		t.Errorf("5 hours with a daily cap of 4 penalty = %d, want %d", p, DefaultFitnessWeights.TeacherHour)
+	}
+	if p := s.teacherHoursPenalty(week(output.Day{g, g, g, g}, output.Day{g, g, g, g}), in); p != 2*DefaultFitnessWeights.TeacherHour {
+		t.Errorf("8 hours with a weekly cap of 6 penalty = %d, want %d", p, 2*DefaultFitnessWeights.TeacherHour)
+	}
+}
+
+func TestShortDayPenalty(t *testing.T) {
+	in := input.InputData{Divisions: []input.Division{{Name: "A"}}}
+	scattered, even := loads(1, 1, 1, 6, 6), loads(3, 3, 3, 3, 3)
+
+	s := &Solver{}
+	if p := s.shortDayPenalty(even); p != 0 {
+		t.Errorf("even week penalty = %d, want 0", p)
+	}
+	// The mean is 3, so each of the single lesson days is a lesson short of half of it
+	if p := s.shortDayPenalty(scattered); p != 3*DefaultFitnessWeights.ShortDay {
+		t.Errorf("1, 1, 1, 6, 6 penalty = %d, want %d", p, 3*DefaultFitnessWeights.ShortDay)
+	}
+
+	// Even without the imbalance term the short days make the week worse
+	s = weighted(func(w *FitnessWeights) { w.Imbalance = 0 })
+	if sc, ev := s.Evaluate(scattered, in).Total(), s.Evaluate(even, in).Total(); sc <= ev {
+		t.Errorf("1, 1, 1, 6, 6 total = %d, want more than the even week's %d", sc, ev)
+	}
+}
+
+func TestPreferredDivisionPenalty(t *testing.T) {
+	a := input.GlobalSubject("a")
+	lj := input.Teacher("LJ")
+	in := input.InputData{
+		Divisions:          []input.Division{{Name: "1"}, {Name: "2"}},
+		TeacherConstraints: map[input.Teacher]input.TeacherConstraints{lj: {PreferredDivisions: []string{"2"}}},
+	}
+	s := &Solver{}
+
+	// A lesson of LJ in one of the divisions, the other one is taught by nobody
+	teaching := func(dIdx int) Individual {
+		ind := Individual{Timetables: []output.Days{{nil}, {nil}}}
+		ind.Timetables[dIdx][0] = output.Day{taught(&a, &lj)}
+		return ind
+	}
+	if p := s.preferredDivisionPenalty(teaching(1), in); p != 0 {
+		t.Errorf("teaching the preferred division penalty = %d, want 0", p)
+	}
+	// Division 1 has no subject naming LJ, so nothing pins them to the lesson
+	if p := s.preferredDivisionPenalty(teaching(0), in); p != DefaultFitnessWeights.PreferredDivision {
+		t.Errorf("teaching division 1 unpinned penalty = %d, want %d", p, DefaultFitnessWeights.PreferredDivision)
+	}
+
+	// The input pins LJ to the subject, the solver can't hand it to anyone else
+	in.Divisions[0].Subjects = []input.Subject{{GlobalSubject: &a, Teacher: &lj, Allocation: input.Allocation{1}}}
+	if p := s.preferredDivisionPenalty(teaching(0), in); p != 0 {
+		t.Errorf("teaching division 1 pinned penalty = %d, want 0", p)
+	}
+	if b := s.Evaluate(teaching(0), in); b.PreferredDivisions != 0 {
+		t.Errorf("pinned teacher breakdown = %+v, want no preferred division penalty", b)
+	}
+}
+
+func TestPreferredDayPenalty(t *testing.T) {
+	pe := input.GlobalSubject("wf")
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &pe, Allocation: input.Allocation{2}, PreferredDays: []int{1, 3}},
+	}}}}
+	double := output.Day{{{GlobalSubject: &pe, Block: 1}}, {{GlobalSubject: &pe, Block: 1}}}
+	s := &Solver{}
+
+	if p := s.preferredDayPenalty(week(nil, double, nil, nil, nil), in); p != 0 {
+		t.Errorf("Tuesday penalty = %d, want 0", p)
+	}
+	// Both hours of the block make up a single chunk
+	if p := s.preferredDayPenalty(week(double, nil, nil, nil, nil), in); p != DefaultFitnessWeights.PreferredDay {
+		t.Errorf("Monday penalty = %d, want %d", p, DefaultFitnessWeights.PreferredDay)
+	}
+}
+
+func TestWeightedImbalancePenalty(t *testing.T) {
+	light := input.InputData{Divisions: []input.Division{{Name: "A", Weight: 1}}}
+	heavy := input.InputData{Divisions: []input.Division{{Name: "B", Weight: 3}}}
+	uneven := loads(8, 4, 4, 4, 0)
+
+	for _, s := range []*Solver{{}, {ImbalanceMode: ImbalanceThreshold}} {
+		l, h := s.imbalancePenalty(uneven, light), s.imbalancePenalty(uneven, heavy)
+		if l == 0 || h != 3*l {
+			t.Errorf("mode %v: weight 1 penalty = %d and weight 3 penalty = %d, want the heavier one 3 times a nonzero lighter one", s.ImbalanceMode, l, h)
+		}
+	}
+}
+
+func TestBuildingChangePenalty(t *testing.T) {
+	a := input.GlobalSubject("a")
+	lj := input.Teacher("LJ")
+	r107, r12, sg4 := input.Classroom{Name: "107", Building: "A"}, input.Classroom{Name: "12", Building: "A"}, input.Classroom{Name: "sg4", Building: "B"}
+	in := input.InputData{Classrooms: []input.Classroom{r107, r12, sg4}}
+	lessonIn := func(room input.Classroom) output.SubjectsGroup {
+		return output.SubjectsGroup{{GlobalSubject: &a, Teacher: &lj, Classroom: &room}}
+	}
+	// LJ teaches the first division in 107 and then the second one in the given room
+	then := func(room input.Classroom, slot int) Individual {
+		second := make(output.Day, slot+1)
+		second[slot] = lessonIn(room)
+		return Individual{Timetables: []output.Days{{output.Day{lessonIn(r107)}}, {second}}}
+	}
+	s := &Solver{}
+
+	if p := s.buildingChangePenalty(then(r12, 1), in); p != 0 {
+		t.Errorf("back to back in the same building penalty = %d, want 0", p)
+	}
+	if p := s.buildingChangePenalty(then(sg4, 1), in); p != DefaultFitnessWeights.BuildingChange {
+		t.Errorf("back to back in buildings far apart penalty = %d, want %d", p, DefaultFitnessWeights.BuildingChange)
+	}
+	if p := s.buildingChangePenalty(then(sg4, 2), in); p != 0 {
+		t.Errorf("a free slot to change buildings penalty = %d, want 0", p)
+	}
+}
+
+func TestLateSubjectPenalty(t *testing.T) {
+	math, art := input.GlobalSubject("matematyka"), input.GlobalSubject("plastyka")
+	in := input.InputData{
+		Divisions:         []input.Division{{Name: "A"}},
+		GlobalSubjectInfo: map[input.GlobalSubject]input.SubjectInfo{math: {Difficulty: 3}},
+	}
+	s := &Solver{}
+
+	first := s.Evaluate(week(lessons(&math, &art, &art, &art)), in)
+	last := s.Evaluate(week(lessons(&art, &art, &art, &math)), in)
+	if first.LateSubjects != 0 {
+		t.Errorf("difficult subject in the first slot penalty = %d, want 0", first.LateSubjects)
+	}
+	if want := 3 * 3 * DefaultFitnessWeights.LateSubject; last.LateSubjects != want {
+		t.Errorf("difficult subject in the last slot penalty = %d, want %d", last.LateSubjects, want)
+	}
+	if first.Total() >= last.Total() {
+		t.Errorf("difficult subject first total = %d, want less than last %d", first.Total(), last.Total())
+	}
+}
+
+func TestSubjectPairPenalty(t *testing.T) {
+	theory, lab, other := input.GlobalSubject("fizyka"), input.GlobalSubject("lab"), input.GlobalSubject("a")
+	in := input.InputData{Divisions: []input.Division{{Name: "A", SubjectPairs: []input.SubjectPair{{First: theory, Then: lab}}}}}
+	s := &Solver{}
+
+	tests := []struct {
+		name string
+		day  output.Day
+		want int
+	}{
+		{"adjacent", lessons(&other, &theory, &lab), 0},
+		{"apart", lessons(&theory, &other, &lab), 2 * DefaultFitnessWeights.SubjectPair},
+		{"reversed", lessons(&lab, &theory), 2 * DefaultFitnessWeights.SubjectPair},
+	}
+	for _, tt := range tests {
+		if got := s.subjectPairPenalty(week(tt.day), in); got != tt.want {
+			t.Errorf("%s: penalty = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOverfullSlots(t *testing.T) {
+	english := input.GlobalSubject("angielski")
+	in := input.InputData{Divisions: []input.Division{{Name: "A"}}}
+	groups := append(input.AllGroups()[1:], input.SubjectsGroupOne)
+	var slot output.SubjectsGroup
+	for i := range groups {
+		slot = append(slot, output.Subject{GlobalSubject: &english, Group: &groups[i]})
+	}
+	s := &Solver{}
+
+	full := s.hardViolations(week(output.Day{slot[:output.MaxParallelGroups]}, nil, nil, nil, nil), in)
+	over := s.hardViolations(week(output.Day{slot}, nil, nil, nil, nil), in)
+	if full.OverfullSlots != 0 {
+		t.Errorf("%d parallel groups are %d overfull, want 0", output.MaxParallelGroups, full.OverfullSlots)
+	}
+	if over.OverfullSlots != 1 {
+		t.Errorf("%d parallel groups are %d overfull, want 1", len(slot), over.OverfullSlots)
+	}
+}
+
+func TestRepeatedDayPenalty(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	none := input.SubjectsGroupNone
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &a, Group: none, Allocation: input.Allocation{2, 1}},
+		{GlobalSubject: &b, Group: none, Allocation: input.Allocation{1}},
+	}}}}
+	block := output.SubjectsGroup{{GlobalSubject: &a, Group: &none, Block: 1}}
+	single := output.SubjectsGroup{{GlobalSubject: &a, Group: &none}}
+	other := output.SubjectsGroup{{GlobalSubject: &b}}
+	s := &Solver{}
+
+	sameDay := week(output.Day{block, block, other, single}, nil, nil, nil, nil)
+	apart := week(output.Day{block, block, other}, output.Day{single}, nil, nil, nil)
+	if p := s.repeatedDayPenalty(apart, in); p != 0 {
+		t.Errorf("chunks on separate days penalty = %d, want 0", p)
+	}
+	if p := s.repeatedDayPenalty(sameDay, in); p != DefaultFitnessWeights.RepeatedDay {
+		t.Errorf("chunks on the same day penalty = %d, want %d", p, DefaultFitnessWeights.RepeatedDay)
+	}
+
+	// A five day timetable checked against a six day week isn't out of range
+	s = &Solver{DaysPerWeek: 6}
+	if p := s.repeatedDayPenalty(sameDay, in); p != DefaultFitnessWeights.RepeatedDay {
+		t.Errorf("six day week penalty = %d, want %d", p, DefaultFitnessWeights.RepeatedDay)
+	}
+}
+
+func TestPaddedDayLoads(t *testing.T) {
+	in := input.InputData{Divisions: []input.Division{{Name: "A"}}}
+	balanced := loads(4, 4, 4, 4, 4)
+	padded := balanced.Clone()
+	// A late start pads Monday with free slots, it's still a day of 4 lessons
+	padded.Timetables[0][0] = append(output.Day{{}, {}}, padded.Timetables[0][0]...)
+
+	s := weighted(func(w *FitnessWeights) { w.FrontLoad = 10 })
+	for name, penalty := range map[string]func(Individual) int{
+		"imbalance":  func(ind Individual) int { return s.imbalancePenalty(ind, in) },
+		"short day":  s.shortDayPenalty,
+		"front load": s.frontLoadPenalty,
+	} {
+		if b, p := penalty(balanced), penalty(padded); b != p {
+			t.Errorf("%s penalty = %d with free slots, want the unpadded %d", name, p, b)
+		}
+	}
+}
+
+// weighted returns a solver with the default weights changed by set.
+func weighted(set func(w *FitnessWeights)) *Solver {
+	w := DefaultFitnessWeights
+	set(&w)
+	return &Solver{Weights: &w}
+}
@@ -0,0 +1,135 @@
+// core/solver/crossover.go
+package solver
+
+import (
+	"math/rand"
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+/* Chunk crossover
+Swapping whole days between parents drops the chunks of the replaced day and duplicates the
+ones that moved between days, so most children came out with broken allocations. Instead a
+random division of the child is rebuilt from the chunks of the first parent, a multi-hour block
+or a single hour, and every chunk takes its day and slot from either parent with the same odds.
+A chunk missing from the second parent keeps its place, so the child has exactly the lessons of
+the first one. The chunks of a day are laid out in the order of their slots without gaps, after
+the padding of the parent its first chunk came from.
+*/
+
+// The identity of a chunk, shared by the same chunk in every individual of a solve
+type chunkKey struct {
+	globalSubject input.GlobalSubject
+	teacher       input.Teacher
+	group         input.SubjectsGroupType
+	block         uint // Blocks are numbered the same way in every individual
+	n             int  // The occurrence of a single hour among the hours of its subject
+}
+
+// A chunk as placed in a parent
+type chunkPlacement struct {
+	day, slot int
+	padding   int // Empty groups before the first lesson of the day
+	groups    []output.SubjectsGroup
+}
+
+// crossover builds a copy of p1 in which every chunk of a random division takes its
+// placement from p1 or p2, the fixed days are kept as they are.
+func (s *Solver) crossover(rng *rand.Rand, p1, p2 Individual) Individual {
+	// The child gets its own days, so mutating it never scrambles the parents
+	child := p1.Clone()
+	if len(child.Timetables) > 0 {
+		dx := rng.Intn(len(child.Timetables))
+		keys, from1 := s.chunkPlacements(p1.Timetables[dx])
+		_, from2 := s.chunkPlacements(p2.Timetables[dx])
+
+		type childChunk struct {
+			chunkPlacement
+			order int
+		}
+		var chunks []childChunk
+		for i, key := range keys {
+			c := from1[key]
+			if o, ok := from2[key]; ok && o.day < len(child.Timetables[dx]) && rng.Intn(2) == 0 {
+				c = o
+			}
+			chunks = append(chunks, childChunk{c, i})
+		}
+		slices.SortStableFunc(chunks, func(a, b childChunk) int {
+			if a.day != b.day {
+				return a.day - b.day
+			}
+			if a.slot != b.slot {
+				return a.slot - b.slot
+			}
+			return a.order - b.order
+		})
+
+		for _, day := range s.freeDays() {
+			if day < len(child.Timetables[dx]) {
+				child.Timetables[dx][day] = child.Timetables[dx][day][:0]
+			}
+		}
+		for _, c := range chunks {
+			d := child.Timetables[dx][c.day]
+			// The first chunk of a day follows its parent's padding, the rest follow the day's last lesson
+			slot := len(d)
+			if slot == 0 {
+				slot = c.padding
+			}
+			for _, sg := range c.groups {
+				d = d.Place(slot, slices.Clone(sg))
+				slot++
+			}
+			child.Timetables[dx][c.day] = d
+		}
+	}
+	return child
+}
+
+// chunkPlacements finds the chunks placed on the free days of a division, it returns their
+// keys in the order they were found and where each one is placed.
+func (s *Solver) chunkPlacements(days output.Days) ([]chunkKey, map[chunkKey]chunkPlacement) {
+	var keys []chunkKey
+	placements := make(map[chunkKey]chunkPlacement)
+	for _, day := range s.freeDays() {
+		if day >= len(days) {
+			continue
+		}
+		d := days[day]
+		padding := slices.IndexFunc(d, func(sg output.SubjectsGroup) bool { return !sg.IsEmpty() })
+		for slot, sg := range d {
+			if sg.IsEmpty() {
+				continue
+			}
+
+			// Lessons are placed in the first subject of a group
+			subj := sg[0]
+			key := chunkKey{
+				globalSubject: deref(subj.GlobalSubject),
+				teacher:       deref(subj.Teacher),
+				group:         deref(subj.Group),
+				block:         subj.Block,
+			}
+			if subj.Block == 0 {
+				for {
+					if _, ok := placements[key]; !ok {
+						break
+					}
+					key.n++
+				}
+			} else if c, ok := placements[key]; ok {
+				// Later hours of a block join its first one
+				c.groups = append(c.groups, sg)
+				placements[key] = c
+				continue
+			}
+
+			keys = append(keys, key)
+			placements[key] = chunkPlacement{day: day, slot: slot, padding: padding, groups: []output.SubjectsGroup{sg}}
+		}
+	}
+	return keys, placements
+}
@@ -0,0 +1,105 @@
+// core/solver/crossover_test.go
+package solver
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"math/rand"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestCrossoverKeepsParents(t *testing.T) {
+	in := input.ExampleInputData
+	s := &Solver{MutationRate: 1}
+	rng := rand.New(rand.NewSource(1))
+	p1, p2 := s.randomIndividual(rng, in), s.randomIndividual(rng, in)
+	want1, _ := json.Marshal(p1)
+	want2, _ := json.Marshal(p2)
+
+	for range 20 {
+		child := s.crossover(rng, p1, p2)
+		for range 10 {
+			s.mutate(rng, &child, in, 1)
+		}
+		s.repairTeacherOverlap(rng, &child, 1)
+	}
+
+	if got, _ := json.Marshal(p1); !bytes.Equal(got, want1) {
+		t.Error("mutating the children changed the first parent")
+	}
+	if got, _ := json.Marshal(p2); !bytes.Equal(got, want2) {
+		t.Error("mutating the children changed the second parent")
+	}
+}
+
+// dayCrossover is the crossover that came before chunks, it copies two random days of
+// a random division from p2 into p1.
+func dayCrossover(rng *rand.Rand, p1, p2 Individual) Individual {
+	child := p1.Clone()
+	dx := rng.Intn(len(child.Timetables))
+	for range 2 {
+		day := rng.Intn(len(child.Timetables[dx]))
+		child.Timetables[dx][day] = p2.Timetables[dx][day].Clone()
+	}
+	return child
+}
+
+// The lessons of a subject, teacher and group in a division
+type lessonKey struct {
+	division int
+	chunkKey
+}
+
+// lessonCounts returns the number of hours of every subject, teacher and group of an individual.
+func lessonCounts(ind Individual) map[lessonKey]int {
+	counts := make(map[lessonKey]int)
+	for dIdx, days := range ind.Timetables {
+		for _, d := range days {
+			for _, sg := range d {
+				for _, subj := range sg {
+					if subj.GlobalSubject != nil {
+						key := chunkKey{globalSubject: *subj.GlobalSubject, teacher: deref(subj.Teacher), group: deref(subj.Group)}
+						counts[lessonKey{dIdx, key}]++
+					}
+				}
+			}
+		}
+	}
+	return counts
+}
+
+func TestCrossoverAllocation(t *testing.T) {
+	in := input.ExampleInputData
+	s := &Solver{MoveMutationRate: 0.5}
+	rng := rand.New(rand.NewSource(1))
+	// Fresh individuals share their days, the parents move lessons between days first
+	parent := func() Individual {
+		ind := s.randomIndividual(rng, in)
+		for range 20 {
+			s.mutate(rng, &ind, in, 1)
+		}
+		return ind
+	}
+
+	// Every parent has all the hours of the input, a child is complete when it has them too
+	complete := func(cross func(*rand.Rand, Individual, Individual) Individual) int {
+		n := 0
+		for range 50 {
+			p1, p2 := parent(), parent()
+			if maps.Equal(lessonCounts(cross(rng, p1, p2)), lessonCounts(p1)) {
+				n++
+			}
+		}
+		return n
+	}
+	byDay, byChunk := complete(dayCrossover), complete(s.crossover)
+	if byChunk != 50 {
+		t.Errorf("%d of 50 chunk crossover children have every hour, want all of them", byChunk)
+	}
+	if byDay >= byChunk {
+		t.Errorf("%d of 50 day crossover children have every hour, want fewer than the chunk crossover's %d", byDay, byChunk)
+	}
+}
@@ -0,0 +1,49 @@
+// core/solver/estimate.go
+package solver
+
+import (
+	"math/big"
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+// SearchSpaceSize estimates how many distinct timetables exist for the input data,
+// it's meant as an order of magnitude to explain why bigger inputs need more generations.
+// Every subject chunk can be placed on any day of a 5 day week and every hour of it can be
+// taught in any of the subject's allowed or preferred classrooms, or in any set of RoomsNeeded
+// of them when it needs several, the order of chunks within a day is ignored.
+func SearchSpaceSize(in input.InputData) *big.Int {
+	var s Solver
+	days := big.NewInt(int64(s.daysPerWeek()))
+	size := big.NewInt(1)
+
+	for _, div := range in.Divisions {
+		for _, chunk := range s.extractSubjectChunks(div) {
+			size.Mul(size, days)
+
+			if rooms := roomChoices(chunk.subj); rooms.Cmp(big.NewInt(1)) > 0 {
+				size.Mul(size, new(big.Int).Exp(rooms, big.NewInt(int64(chunk.size)), nil))
+			}
+		}
+	}
+	return size
+}
+
+// roomChoices returns the number of ways a single hour of the subject can be given its classrooms.
+func roomChoices(subj input.Subject) *big.Int {
+	var names []string
+	for _, rooms := range [][]*input.Classroom{subj.Classrooms, subj.PreferredClassrooms} {
+		for _, c := range rooms {
+			if c != nil && !slices.Contains(names, c.Name) {
+				names = append(names, c.Name)
+			}
+		}
+	}
+
+	pool, needed := int64(len(names)), int64(max(subj.RoomsNeeded, 1))
+	if pool <= needed {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Binomial(pool, needed)
+}
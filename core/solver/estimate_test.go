@@ -0,0 +1,25 @@
+// core/solver/estimate_test.go
+package solver
+
+import (
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestSearchSpaceSize(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	r1, r2, r3 := &input.Classroom{Name: "1"}, &input.Classroom{Name: "2"}, &input.Classroom{Name: "3"}
+	in := input.InputData{Divisions: []input.Division{{Subjects: []input.Subject{
+		// 3 rooms for every hour, a 2 hour chunk and a single hour
+		{GlobalSubject: &a, Allocation: input.Allocation{2, 1}, Classrooms: []*input.Classroom{r1, r2}, PreferredClassrooms: []*input.Classroom{r2, r3}},
+		// 3 pairs of rooms for its single hour
+		{GlobalSubject: &b, Allocation: input.Allocation{1}, Classrooms: []*input.Classroom{r1, r2, r3}, RoomsNeeded: 2},
+	}}}}
+
+	// (5 days * 3^2 rooms) * (5 days * 3 rooms) * (5 days * 3 pairs)
+	want := int64(45 * 15 * 15)
+	if got := SearchSpaceSize(in); !got.IsInt64() || got.Int64() != want {
+		t.Errorf("SearchSpaceSize = %v, want %d", got, want)
+	}
+}
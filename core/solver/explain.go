@@ -0,0 +1,93 @@
+// core/solver/explain.go
+package solver
+
+import (
+	"fmt"
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+// Explain annotates every placed subject of a solved timetable with the constraints
+// it satisfies and the penalties it incurs, e.g. for tooltips in the UI.
+func (s *Solver) Explain(out output.OutputData, in input.InputData) output.AnnotatedOutputData {
+	usage := slotUsageOf(out.DivisionsTimetables)
+
+	result := output.AnnotatedOutputData{
+		DivisionsTimetables: make([]output.AnnotatedDays, len(out.DivisionsTimetables)),
+		Fitness:             out.Fitness,
+	}
+	for dIdx, divTT := range out.DivisionsTimetables {
+		result.DivisionsTimetables[dIdx] = make(output.AnnotatedDays, len(divTT))
+		for day := range divTT {
+			annotatedDay := make(output.AnnotatedDay, 0, len(divTT[day]))
+			for slot, sg := range divTT[day] {
+				key := slotKey{day: day, slot: slot}
+				group := output.AnnotatedSubjectsGroup{}
+				for _, subj := range sg {
+					if subj.GlobalSubject == nil {
+						continue
+					}
+
+					var a output.Annotation
+					if subj.Teacher != nil {
+						if usage.teachers[key][*subj.Teacher] > 1 {
+							a.Penalties = append(a.Penalties, fmt.Sprintf("teacher %s overlaps with another lesson", *subj.Teacher))
+						} else {
+							a.Satisfied = append(a.Satisfied, "no teacher overlap")
+						}
+					}
+					for _, c := range subj.Classrooms() {
+						if usage.classrooms[key][c.Name] > 1 {
+							a.Penalties = append(a.Penalties, fmt.Sprintf("classroom %s overlaps with another lesson", *c))
+						} else {
+							a.Satisfied = append(a.Satisfied, "no classroom overlap")
+						}
+					}
+					if dIdx < len(in.Divisions) {
+						s.explainSubject(&a, in.Divisions[dIdx], subj, slot, len(divTT[day]))
+					}
+
+					group = append(group, output.AnnotatedSubject{Subject: subj, Annotation: a})
+				}
+				annotatedDay = append(annotatedDay, group)
+			}
+			result.DivisionsTimetables[dIdx][day] = annotatedDay
+		}
+	}
+
+	return result
+}
+
+// explainSubject annotates the subject specific constraints of a placed subject.
+func (s *Solver) explainSubject(a *output.Annotation, div input.Division, placed output.Subject, slot, dayLen int) {
+	idx := slices.IndexFunc(div.Subjects, func(subj input.Subject) bool {
+		return placedAs(placed, subj)
+	})
+	if idx < 0 {
+		a.Penalties = append(a.Penalties, "subject is not allocated to the division")
+		return
+	}
+	subj := div.Subjects[idx]
+
+	preferred := subj.PreferredClassrooms
+	if len(preferred) == 0 {
+		preferred = subj.Classrooms
+	}
+	if len(preferred) > 0 && placed.Classroom != nil {
+		if hasClassroom(preferred, placed.Classroom) {
+			a.Satisfied = append(a.Satisfied, "preferred classroom")
+		} else {
+			a.Penalties = append(a.Penalties, "classroom is not one of the preferred classrooms")
+		}
+	}
+
+	if subj.Placement != "" && subj.Placement != input.SubjectPlacementAny {
+		if dist := placementDistance(subj.Placement, slot, dayLen); dist > 0 {
+			a.Penalties = append(a.Penalties, fmt.Sprintf("%d slots away from the %s placement", dist, subj.Placement))
+		} else {
+			a.Satisfied = append(a.Satisfied, fmt.Sprintf("%s placement", subj.Placement))
+		}
+	}
+}
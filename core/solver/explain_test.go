@@ -0,0 +1,35 @@
+// core/solver/explain_test.go
+package solver
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+func TestExplain(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	in := input.InputData{Divisions: []input.Division{{Subjects: []input.Subject{
+		{GlobalSubject: &a, Allocation: input.Allocation{1}, Placement: input.SubjectPlacementEdges},
+		{GlobalSubject: &b, Allocation: input.Allocation{2}},
+	}}}}
+	// The edge subject sits in the middle of the day, a slot away from both edges
+	out := output.OutputData{DivisionsTimetables: week(lessons(&b, &a, &b)).Timetables}
+
+	explained := (&Solver{}).Explain(out, in)
+	middle := explained.DivisionsTimetables[0][0][1]
+	if len(middle) != 1 || *middle[0].GlobalSubject != a {
+		t.Fatalf("middle slot = %+v, want subject a", middle)
+	}
+	if !slices.ContainsFunc(middle[0].Annotation.Penalties, func(p string) bool {
+		return strings.Contains(p, "1 slots away from the edges placement")
+	}) {
+		t.Errorf("penalties = %v, want the edges placement penalty", middle[0].Annotation.Penalties)
+	}
+	if first := explained.DivisionsTimetables[0][0][0][0]; len(first.Annotation.Penalties) != 0 {
+		t.Errorf("penalties of the first slot = %v, want none", first.Annotation.Penalties)
+	}
+}
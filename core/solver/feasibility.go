@@ -0,0 +1,103 @@
+// core/solver/feasibility.go
+package solver
+
+import (
+	"fmt"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+// The number of days in a week when Solver.DaysPerWeek isn't set
+const defaultDaysPerWeek = input.DefaultDaysPerWeek
+
+func (s *Solver) daysPerWeek() int {
+	if s.DaysPerWeek > 0 {
+		return s.DaysPerWeek
+	}
+	return defaultDaysPerWeek
+}
+
+// slotsPerDay returns Solver.MaxSlotsPerDay, or the slots of the input's day when it isn't set.
+func (s *Solver) slotsPerDay(in input.InputData) int {
+	if s.MaxSlotsPerDay > 0 {
+		return s.MaxSlotsPerDay
+	}
+	return in.SlotsPerDay()
+}
+
+// TeacherLoadWarnings reports teachers whose combined allocated hours across all
+// divisions exceed the slots available in a week, such inputs can't be scheduled
+// without teacher overlaps however long the solver runs.
+func (s *Solver) TeacherLoadWarnings(in input.InputData) []string {
+	var warnings []string
+	for _, e := range in.TeacherOverloads(s.daysPerWeek() * s.slotsPerDay(in)) {
+		warnings = append(warnings, e.Message)
+	}
+	return warnings
+}
+
+// FeasibilityCheck checks the input data against the default solver, see Solver.FeasibilityCheck.
+func FeasibilityCheck(in input.InputData) (bool, []string) {
+	var s Solver
+	return s.FeasibilityCheck(in)
+}
+
+// FeasibilityCheck is a cheap dry run before solving, it compares the hours of every teacher,
+// division and single classroom with the slots of a week and checks the groups of the subjects,
+// it returns false with the reasons when a bound is clearly violated, so the input can't be
+// scheduled without hard violations however long the solver runs. Passing doesn't guarantee
+// that a feasible timetable exists.
+func (s *Solver) FeasibilityCheck(in input.InputData) (bool, []string) {
+	reasons := s.TeacherLoadWarnings(in)
+	weekSlots := s.daysPerWeek() * s.slotsPerDay(in)
+
+	// Hours of the subjects that can only be taught in that classroom, across divisions
+	roomHours := make(map[string]int)
+	var rooms []string
+
+	for _, div := range in.Divisions {
+		lastSlot := s.slotsPerDay(in)
+		if div.MaxSlotsPerDay > 0 {
+			lastSlot = min(lastSlot, int(div.MaxSlotsPerDay))
+		}
+		daySlots := max(lastSlot-int(div.EarliestStart), 0)
+		for _, subj := range div.Subjects {
+			group := subj.Group
+			if group == "" {
+				group = input.SubjectsGroupNone
+			}
+			if _, err := input.ParseGroup(string(group)); err != nil {
+				reasons = append(reasons, fmt.Sprintf("division %s: subject %s: %v", div.Name, deref(subj.GlobalSubject), err))
+			}
+
+			hours := 0
+			for _, h := range subj.Allocation {
+				hours += int(h)
+				if int(h) > daySlots {
+					reasons = append(reasons, fmt.Sprintf("division %s: subject %s needs %d consecutive hours, but its days have only %d slots",
+						div.Name, deref(subj.GlobalSubject), h, daySlots))
+				}
+			}
+
+			if len(subj.Classrooms) == 1 && subj.Classrooms[0] != nil {
+				room := subj.Classrooms[0].Name
+				if _, ok := roomHours[room]; !ok {
+					rooms = append(rooms, room)
+				}
+				roomHours[room] += hours
+			}
+		}
+	}
+	for _, e := range in.DivisionOverloads(s.daysPerWeek(), s.slotsPerDay(in)) {
+		reasons = append(reasons, e.Message)
+	}
+
+	for _, room := range rooms {
+		if roomHours[room] > weekSlots {
+			reasons = append(reasons, fmt.Sprintf("classroom %s is the only classroom of subjects taught %d hours, but a week has only %d slots",
+				room, roomHours[room], weekSlots))
+		}
+	}
+
+	return len(reasons) == 0, reasons
+}
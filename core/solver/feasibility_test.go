@@ -0,0 +1,59 @@
+// core/solver/feasibility_test.go
+package solver
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestTeacherLoadWarnings(t *testing.T) {
+	s := &Solver{}
+	if warnings := s.TeacherLoadWarnings(input.ExampleInputData); len(warnings) != 0 {
+		t.Errorf("example data warnings = %v, want none", warnings)
+	}
+
+	warnings := s.TeacherLoadWarnings(overbookedInput())
+	if len(warnings) != 1 {
+		t.Fatalf("warnings = %v, want one for LJ", warnings)
+	}
+	for _, want := range []string{`teacher "LJ" is allocated 80 hours`, "1A: 40", "1B: 40", "only 40 slots"} {
+		if !strings.Contains(warnings[0], want) {
+			t.Errorf("warning %q doesn't contain %q", warnings[0], want)
+		}
+	}
+}
+
+func TestFeasibilityCheck(t *testing.T) {
+	s := &Solver{}
+	if ok, reasons := s.FeasibilityCheck(input.ExampleInputData); !ok || len(reasons) != 0 {
+		t.Errorf("example data: feasible = %v with reasons %v, want feasible without any", ok, reasons)
+	}
+
+	ok, reasons := s.FeasibilityCheck(overbookedInput())
+	if ok {
+		t.Fatal("overbooked teacher: feasible = true")
+	}
+	if !slices.ContainsFunc(reasons, func(r string) bool { return strings.Contains(r, `teacher "LJ"`) }) {
+		t.Errorf("reasons = %v, want one for LJ", reasons)
+	}
+}
+
+func TestFeasibilityCheckWeek(t *testing.T) {
+	// 80 hours of LJ fit into neither a 5 nor a 6 day week of 8 slots, but into one of 10 slots
+	in := overbookedInput()
+	for _, tt := range []struct {
+		s        Solver
+		feasible bool
+	}{
+		{Solver{}, false},
+		{Solver{DaysPerWeek: 6}, false},
+		{Solver{DaysPerWeek: 8, MaxSlotsPerDay: 10}, true},
+	} {
+		if ok, reasons := tt.s.FeasibilityCheck(in); ok != tt.feasible {
+			t.Errorf("%d days of %d slots: feasible = %v, want %v: %v", tt.s.daysPerWeek(), tt.s.slotsPerDay(in), ok, tt.feasible, reasons)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// core/solver/fuzz_test.go
+package solver
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+// randomInput returns input data with up to divisions divisions of up to subjects subjects each,
+// every reference resolves, the subjects, teachers and classrooms are picked from small pools so
+// they're shared between divisions, the input may still be infeasible, e.g. an overbooked teacher.
+func randomInput(rng *rand.Rand, divisions, subjects int) input.InputData {
+	var in input.InputData
+	for i := range 4 {
+		in.GlobalSubjects = append(in.GlobalSubjects, input.GlobalSubject(fmt.Sprintf("s%d", i)))
+		in.Teachers = append(in.Teachers, input.Teacher(fmt.Sprintf("t%d", i)))
+		in.Classrooms = append(in.Classrooms, input.Classroom{Name: fmt.Sprintf("c%d", i)})
+	}
+
+	for d := range divisions {
+		div := input.Division{Name: fmt.Sprintf("%dA", d+1)}
+		for range rng.Intn(subjects + 1) {
+			subj := input.Subject{
+				GlobalSubject: &in.GlobalSubjects[rng.Intn(len(in.GlobalSubjects))],
+				Group:         []input.SubjectsGroupType{"", input.SubjectsGroupNone, input.SubjectsGroupOne}[rng.Intn(3)],
+			}
+			// Never all zero, a block of up to 2 hours on up to 3 days
+			subj.Allocation[0] = uint(1 + rng.Intn(2))
+			for i := 1; i < 3; i++ {
+				subj.Allocation[i] = uint(rng.Intn(3))
+			}
+			if rng.Intn(4) > 0 {
+				subj.Teacher = &in.Teachers[rng.Intn(len(in.Teachers))]
+			}
+			for range rng.Intn(3) {
+				subj.Classrooms = append(subj.Classrooms, &in.Classrooms[rng.Intn(len(in.Classrooms))])
+			}
+			div.Subjects = append(div.Subjects, subj)
+		}
+		in.Divisions = append(in.Divisions, div)
+	}
+	return in
+}
+
+// FuzzSolve solves random but structurally valid inputs, including ones without divisions
+// or with empty divisions, a solve must never panic and always returns a timetable per division.
+func FuzzSolve(f *testing.F) {
+	f.Fuzz(func(t *testing.T, seed int64, divisions, subjects uint8) {
+		rng := rand.New(rand.NewSource(seed))
+		in := randomInput(rng, int(divisions%5), int(subjects%8))
+		if errs := input.OrphanedReferences(in); len(errs) > 0 {
+			t.Fatalf("random input has dangling references %v", errs)
+		}
+
+		s := &Solver{PopulationSize: 4, Generations: 3, MutationRate: 0.5, MoveMutationRate: 0.5, Seed: seed | 1}
+		out := s.Solve(in)
+		if len(out.DivisionsTimetables) != len(in.Divisions) {
+			t.Fatalf("solved %d divisions, want %d", len(out.DivisionsTimetables), len(in.Divisions))
+		}
+	})
+}
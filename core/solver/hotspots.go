@@ -0,0 +1,236 @@
+// core/solver/hotspots.go
+package solver
+
+import (
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+type slotKey struct {
+	day  int
+	slot int
+}
+
+// How many subjects groups use each teacher and classroom in each slot across all divisions,
+// classrooms are told apart by their names, the same room may be decoded into separate values
+type slotUsage struct {
+	teachers   map[slotKey]map[input.Teacher]int
+	classrooms map[slotKey]map[string]int
+}
+
+// parallelClashes counts the parallel groups of a subjects group that reuse
+// a teacher or a classroom of another group taught at the same time.
+func parallelClashes(sg output.SubjectsGroup) int {
+	clashes := 0
+	for i, a := range sg {
+		if a.GlobalSubject == nil {
+			continue
+		}
+		for _, b := range sg[:i] {
+			if b.GlobalSubject == nil {
+				continue
+			}
+			if (a.Teacher != nil && equalValues(a.Teacher, b.Teacher)) || sharesClassroom(a, b) {
+				clashes++
+				break
+			}
+		}
+	}
+	return clashes
+}
+
+// sharesClassroom reports whether two lessons are taught in a common classroom.
+func sharesClassroom(a, b output.Subject) bool {
+	for _, c := range a.Classrooms() {
+		if slices.ContainsFunc(b.Classrooms(), func(o *input.Classroom) bool { return o.Name == c.Name }) {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicateGroups counts the parallel entries of a subjects group whose group already
+// has a lesson in the slot, the whole division isn't a parallel group, see sharesWholeDivisionSlot.
+func duplicateGroups(sg output.SubjectsGroup) int {
+	duplicates := 0
+	var groups []input.SubjectsGroupType
+	for _, subj := range sg {
+		if subj.GlobalSubject == nil || subj.Group == nil || *subj.Group == "" || *subj.Group == input.SubjectsGroupNone {
+			continue
+		}
+		if slices.Contains(groups, *subj.Group) {
+			duplicates++
+		} else {
+			groups = append(groups, *subj.Group)
+		}
+	}
+	return duplicates
+}
+
+// overfullLessons returns the number of lessons of the slot beyond output.MaxParallelGroups.
+func overfullLessons(sg output.SubjectsGroup) int {
+	placed := 0
+	for _, subj := range sg {
+		if subj.GlobalSubject != nil {
+			placed++
+		}
+	}
+	return max(placed-output.MaxParallelGroups, 0)
+}
+
+func slotUsageOf(timetables []output.Days) slotUsage {
+	usage := slotUsage{
+		teachers:   make(map[slotKey]map[input.Teacher]int),
+		classrooms: make(map[slotKey]map[string]int),
+	}
+
+	for _, divTT := range timetables {
+		for day := range divTT {
+			for slot, sg := range divTT[day] {
+				key := slotKey{day: day, slot: slot}
+				// Parallel groups reusing a teacher or classroom are counted by parallelClashes
+				var teachers []input.Teacher
+				var classrooms []string
+				for _, subj := range sg {
+					if subj.GlobalSubject == nil {
+						continue
+					}
+					if subj.Teacher != nil && !slices.Contains(teachers, *subj.Teacher) {
+						teachers = append(teachers, *subj.Teacher)
+						if usage.teachers[key] == nil {
+							usage.teachers[key] = make(map[input.Teacher]int)
+						}
+						usage.teachers[key][*subj.Teacher]++
+					}
+					for _, c := range subj.Classrooms() {
+						if slices.Contains(classrooms, c.Name) {
+							continue
+						}
+						classrooms = append(classrooms, c.Name)
+						if usage.classrooms[key] == nil {
+							usage.classrooms[key] = make(map[string]int)
+						}
+						usage.classrooms[key][c.Name]++
+					}
+				}
+			}
+		}
+	}
+	return usage
+}
+
+// A slot in which teachers or classrooms are booked more than once
+type Hotspot struct {
+	Day               int
+	Slot              int
+	TeacherOverlaps   int
+	ClassroomOverlaps int
+	Teachers          []input.Teacher // The double booked teachers
+	Classrooms        []string        // The names of the double booked classrooms
+}
+
+// Overlaps returns the total number of overlaps in the slot.
+func (h Hotspot) Overlaps() int {
+	return h.TeacherOverlaps + h.ClassroomOverlaps
+}
+
+// Hotspots returns the slots where teacher and classroom overlaps concentrate, ranked from
+// the most overlapping one, it guides manual intervention when no feasible timetable is found.
+func Hotspots(ind Individual, in input.InputData) []Hotspot {
+	usage := slotUsageOf(ind.Timetables)
+	spots := make(map[slotKey]*Hotspot)
+	spot := func(key slotKey) *Hotspot {
+		if spots[key] == nil {
+			spots[key] = &Hotspot{Day: key.day, Slot: key.slot}
+		}
+		return spots[key]
+	}
+
+	for key, teachers := range usage.teachers {
+		for teacher, n := range teachers {
+			if n > 1 {
+				h := spot(key)
+				h.TeacherOverlaps += n - 1
+				h.Teachers = append(h.Teachers, teacher)
+			}
+		}
+	}
+	for key, classrooms := range usage.classrooms {
+		for classroom, n := range classrooms {
+			if n > 1 {
+				h := spot(key)
+				h.ClassroomOverlaps += n - 1
+				h.Classrooms = append(h.Classrooms, classroom)
+			}
+		}
+	}
+
+	hotspots := make([]Hotspot, 0, len(spots))
+	for _, h := range spots {
+		slices.Sort(h.Teachers)
+		slices.Sort(h.Classrooms)
+		hotspots = append(hotspots, *h)
+	}
+	slices.SortFunc(hotspots, func(a, b Hotspot) int {
+		if a.Overlaps() != b.Overlaps() {
+			return b.Overlaps() - a.Overlaps()
+		}
+		if a.Day != b.Day {
+			return a.Day - b.Day
+		}
+		return a.Slot - b.Slot
+	})
+	return hotspots
+}
+
+// weightedOverlaps counts the teacher and classroom overlaps like overlapViolations,
+// but each overlap is multiplied by the highest weight of the divisions involved.
+func weightedOverlaps(timetables []output.Days, in input.InputData, usage slotUsage) (teachers, classrooms int) {
+	type teacherKey struct {
+		slotKey
+		teacher input.Teacher
+	}
+	type classroomKey struct {
+		slotKey
+		classroom string
+	}
+	teacherWeights := make(map[teacherKey]int)
+	classroomWeights := make(map[classroomKey]int)
+
+	for dIdx, divTT := range timetables {
+		weight := 1
+		if dIdx < len(in.Divisions) {
+			weight = divisionWeight(in.Divisions[dIdx])
+		}
+		for day := range divTT {
+			for slot, sg := range divTT[day] {
+				key := slotKey{day: day, slot: slot}
+				for _, subj := range sg {
+					if subj.GlobalSubject == nil {
+						continue
+					}
+					if subj.Teacher != nil && usage.teachers[key][*subj.Teacher] > 1 {
+						tk := teacherKey{key, *subj.Teacher}
+						teacherWeights[tk] = max(teacherWeights[tk], weight)
+					}
+					for _, c := range subj.Classrooms() {
+						if usage.classrooms[key][c.Name] > 1 {
+							ck := classroomKey{key, c.Name}
+							classroomWeights[ck] = max(classroomWeights[ck], weight)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for k, w := range teacherWeights {
+		teachers += (usage.teachers[k.slotKey][k.teacher] - 1) * w
+	}
+	for k, w := range classroomWeights {
+		classrooms += (usage.classrooms[k.slotKey][k.classroom] - 1) * w
+	}
+	return teachers, classrooms
+}
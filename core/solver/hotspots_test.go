@@ -0,0 +1,72 @@
+// core/solver/hotspots_test.go
+package solver
+
+import (
+	"slices"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+func TestHotspots(t *testing.T) {
+	math := input.GlobalSubject("matematyka")
+	lj, gr := input.Teacher("LJ"), input.Teacher("gr")
+	r12, r14 := input.Classroom{Name: "12"}, input.Classroom{Name: "14"}
+	lesson := func(teacher *input.Teacher, room *input.Classroom) output.SubjectsGroup {
+		return output.SubjectsGroup{{GlobalSubject: &math, Teacher: teacher, Classroom: room}}
+	}
+
+	// Every division has LJ in classroom 12 on Tuesday slot 3, two of them share gr on Monday,
+	// the rooms are told apart by name, not by the rest of the decoded value
+	free := output.SubjectsGroup{}
+	ind := Individual{Timetables: []output.Days{
+		{{lesson(&gr, &r14)}, {free, free, lesson(&lj, &r12)}},
+		{{lesson(&gr, nil)}, {free, free, lesson(&lj, &input.Classroom{Name: "12", Capacity: 30})}},
+		{nil, {free, free, lesson(&lj, &r12)}},
+	}}
+
+	hotspots := Hotspots(ind, input.InputData{})
+	if len(hotspots) != 2 {
+		t.Fatalf("hotspots = %+v, want 2", hotspots)
+	}
+	top := hotspots[0]
+	if top.Day != 1 || top.Slot != 2 || top.TeacherOverlaps != 2 || top.ClassroomOverlaps != 2 {
+		t.Errorf("top hotspot = %+v, want 2 teacher and 2 classroom overlaps on Tuesday slot 3", top)
+	}
+	if !slices.Equal(top.Teachers, []input.Teacher{lj}) || len(top.Classrooms) != 1 || top.Classrooms[0] != "12" {
+		t.Errorf("top hotspot books %v and %v, want LJ and classroom 12", top.Teachers, top.Classrooms)
+	}
+	if next := hotspots[1]; next.Day != 0 || next.Slot != 0 || next.Overlaps() != 1 {
+		t.Errorf("second hotspot = %+v, want a single overlap on Monday slot 1", next)
+	}
+}
+
+func TestParallelClashes(t *testing.T) {
+	english := input.GlobalSubject("angielski")
+	ak, lj := input.Teacher("AK"), input.Teacher("LJ")
+	r12, r14 := input.Classroom{Name: "12"}, input.Classroom{Name: "14"}
+	one, two := input.SubjectsGroupOne, input.SubjectsGroupTwo
+
+	sharedRoom := output.SubjectsGroup{
+		{GlobalSubject: &english, Teacher: &ak, Classroom: &r12, Group: &one},
+		{GlobalSubject: &english, Teacher: &lj, Classroom: &r12, Group: &two},
+	}
+	ownRooms := output.SubjectsGroup{
+		{GlobalSubject: &english, Teacher: &ak, Classroom: &r12, Group: &one},
+		{GlobalSubject: &english, Teacher: &lj, Classroom: &r14, Group: &two},
+	}
+	if n := parallelClashes(sharedRoom); n != 1 {
+		t.Errorf("groups sharing a room: %d clashes, want 1", n)
+	}
+	if n := parallelClashes(ownRooms); n != 0 {
+		t.Errorf("groups in their own rooms: %d clashes, want 0", n)
+	}
+
+	s := &Solver{}
+	in := input.InputData{Divisions: []input.Division{{Name: "A"}}}
+	shared, own := week(output.Day{sharedRoom}), week(output.Day{ownRooms})
+	if s.hardViolations(shared, in).ParallelClashes != 1 || s.fitness(shared, in) <= s.fitness(own, in) {
+		t.Error("groups sharing a room aren't penalized")
+	}
+}
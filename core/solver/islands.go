@@ -0,0 +1,187 @@
+// core/solver/islands.go
+package solver
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+const (
+	// The number of generations between migrations when Solver.MigrationInterval isn't set
+	defaultMigrationInterval = 50
+	// The number of the fittest individuals of an island that migrate to the next one
+	islandMigrants = 2
+	// The distance between the seeds of consecutive islands, large so they don't
+	// share generators with SeedPerGeneration
+	islandSeedStride = 1_000_003
+)
+
+func (s *Solver) islands() int {
+	return max(s.Islands, 1)
+}
+
+func (s *Solver) migrationInterval() int {
+	if s.MigrationInterval > 0 {
+		return s.MigrationInterval
+	}
+	return defaultMigrationInterval
+}
+
+// runIslands evolves Solver.Islands populations side by side, each of PopulationSize
+// individuals, every MigrationInterval generations the fittest individuals of every
+// island replace the least fit ones of the next island in a ring. The returned state
+// holds the best individual across all islands, along with the number of migrations.
+func (s *Solver) runIslands(ctx context.Context, in input.InputData) (*solveState, int) {
+	seed := s.Seed
+	if seed == 0 {
+		seed = randomSeed()
+	}
+
+	n := s.islands()
+	// Islands share the parallelism, checkpoints and callbacks are reported across islands
+	island := *s
+	island.Islands = 1
+	island.MaxParallelism = max(s.parallelism()/n, 1)
+	island.OnCheckpoint = nil
+	island.OnGeneration = nil
+
+	states := make([]*solveState, n)
+	for i := range states {
+		is := island
+		is.Seed = seed + int64(i)*islandSeedStride
+		states[i] = is.newState(in)
+	}
+
+	migrations := 0
+	sem := make(chan struct{}, s.parallelism())
+	for generation := 0; generation < s.Generations; {
+		generation = min(generation+s.migrationInterval(), s.Generations)
+
+		var wg sync.WaitGroup
+		for _, st := range states {
+			if st.done() {
+				continue
+			}
+			// Acquired before the island starts, so waiting islands don't hold goroutines
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				epoch := island
+				epoch.Generations = generation
+				epoch.run(ctx, st, in)
+			}()
+		}
+		wg.Wait()
+
+		best := bestState(states)
+		if s.OnGeneration != nil {
+			s.OnGeneration(generation-1, best.best.fitness)
+		}
+		if ctx.Err() != nil || best.best.optimal() || allDone(states) || s.budgetSpent() {
+			break
+		}
+		if generation < s.Generations {
+			island.migrate(states, in)
+			migrations++
+		}
+	}
+
+	// The best island carries the result, stopped like the islands that ran the longest
+	result := *bestState(states)
+	for _, st := range states {
+		result.generation = max(result.generation, st.generation)
+	}
+	switch {
+	case ctx.Err() != nil:
+		result.stop = StopCancelled
+	case result.best.optimal():
+		result.stop = StopOptimal
+	case allDone(states) && states[0].stop == StopStagnated:
+		result.stop = StopStagnated
+	case s.budgetSpent():
+		result.stop = StopBudget
+	default:
+		result.stop = StopGenerations
+	}
+	result.seed = seed
+	result.history = islandHistory(states)
+	return &result, migrations
+}
+
+// done reports whether the island stopped before its last generation, because it found
+// an optimal individual or stagnated.
+func (st *solveState) done() bool {
+	return st.stop == StopOptimal || st.stop == StopStagnated
+}
+
+func allDone(states []*solveState) bool {
+	for _, st := range states {
+		if !st.done() {
+			return false
+		}
+	}
+	return true
+}
+
+func bestState(states []*solveState) *solveState {
+	best := states[0]
+	for _, st := range states[1:] {
+		if slices.Compare(st.best.scores, best.best.scores) < 0 {
+			best = st
+		}
+	}
+	return best
+}
+
+// migrate copies the fittest individuals of every island over the least fit individuals
+// of the next island, all migrants are picked before any island is changed.
+func (s *Solver) migrate(states []*solveState, in input.InputData) {
+	ranked := make([][]fitInd, len(states))
+	for i, st := range states {
+		ranked[i], st.cache = s.evaluate(st.pop, in, st.cache)
+		sort.SliceStable(ranked[i], func(a, b int) bool {
+			return slices.Compare(ranked[i][a].scores, ranked[i][b].scores) < 0
+		})
+	}
+
+	for i, st := range states {
+		source := ranked[(i+len(states)-1)%len(states)]
+		k := min(islandMigrants, len(source), len(st.pop))
+		pop := make([]Individual, 0, len(st.pop))
+		for _, f := range ranked[i][:len(ranked[i])-k] {
+			pop = append(pop, f.ind)
+		}
+		for _, f := range source[:k] {
+			pop = append(pop, f.ind.Clone())
+		}
+		st.pop = pop
+	}
+}
+
+// islandHistory merges the histories of the islands into the best fitness across islands
+// after every generation, islands that stopped early keep their last fitness.
+func islandHistory(states []*solveState) []int {
+	generations := 0
+	for _, st := range states {
+		generations = max(generations, len(st.history))
+	}
+
+	history := make([]int, generations)
+	for g := range history {
+		history[g] = math.MaxInt
+		for _, st := range states {
+			if len(st.history) > 0 {
+				history[g] = min(history[g], st.history[min(g, len(st.history)-1)])
+			}
+		}
+	}
+	return history
+}
@@ -0,0 +1,71 @@
+// core/solver/lunch.go
+package solver
+
+import (
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+/* Lunch breaks
+With a lunch window set, every division day that has lessons in all slots of the window misses
+its lunch. The free slot that makes up a lunch break is the only gap a day may have, so it isn't
+counted as a division gap. Repair puts it back after compacting a day, in the first slot of the
+window that doesn't split a block, so the search rarely has to find one by itself.
+*/
+
+func (s *Solver) hasLunchWindow() bool {
+	return s.LunchWindowEnd > 0
+}
+
+func (s *Solver) inLunchWindow(slot int) bool {
+	return s.hasLunchWindow() && slot >= s.LunchWindowStart && slot <= s.LunchWindowEnd
+}
+
+// missesLunch reports whether the day has lessons in every slot of the lunch window.
+func (s *Solver) missesLunch(day output.Day) bool {
+	if !s.hasLunchWindow() || len(day) <= s.LunchWindowEnd {
+		return false
+	}
+	for slot := s.LunchWindowStart; slot <= s.LunchWindowEnd; slot++ {
+		if day[slot].IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// missedLunches counts the days of a division without a lunch break.
+func (s *Solver) missedLunches(days output.Days) int {
+	missed := 0
+	for _, d := range days {
+		if s.missesLunch(d) {
+			missed++
+		}
+	}
+	return missed
+}
+
+// insertLunchBreak inserts an empty subjects group into a day without a lunch break, in the
+// first slot of the window between two lessons that aren't hours of the same block, the day
+// is returned as it is when there's no such slot.
+func (s *Solver) insertLunchBreak(day output.Day) output.Day {
+	if !s.missesLunch(day) {
+		return day
+	}
+	for slot := max(s.LunchWindowStart, 1); slot <= s.LunchWindowEnd; slot++ {
+		before, after := blockOf(day[slot-1]), blockOf(day[slot])
+		if before == 0 || before != after {
+			return slices.Insert(day, slot, output.SubjectsGroup{})
+		}
+	}
+	return day
+}
+
+// blockOf returns the block of the lesson in the subjects group, 0 for a single hour or no lesson.
+func blockOf(sg output.SubjectsGroup) uint {
+	if len(sg) == 0 {
+		return 0
+	}
+	return sg[0].Block
+}
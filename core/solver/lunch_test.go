@@ -0,0 +1,39 @@
+// core/solver/lunch_test.go
+package solver
+
+import (
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestLunchBreak(t *testing.T) {
+	a := input.GlobalSubject("a")
+	in := input.InputData{Divisions: []input.Division{{Name: "A"}}}
+	// Eight lessons booked solid, and the same lessons with slot 5 free for lunch
+	solid := lessons(&a, &a, &a, &a, &a, &a, &a, &a)
+	lunch := lessons(&a, &a, &a, &a, nil, &a, &a, &a, &a)
+	s := &Solver{LunchWindowStart: 3, LunchWindowEnd: 6}
+
+	withLunch, without := s.Evaluate(week(lunch, nil, nil, nil, nil), in), s.Evaluate(week(solid, nil, nil, nil, nil), in)
+	if withLunch.LunchBreaks != 0 || withLunch.DivisionGaps != 0 {
+		t.Errorf("day with a lunch break has lunch penalty %d and gap penalty %d, want neither", withLunch.LunchBreaks, withLunch.DivisionGaps)
+	}
+	if without.LunchBreaks != DefaultFitnessWeights.LunchBreak {
+		t.Errorf("day booked solid lunch penalty = %d, want %d", without.LunchBreaks, DefaultFitnessWeights.LunchBreak)
+	}
+	if withLunch.Total() >= without.Total() {
+		t.Errorf("day with a lunch break total = %d, want less than the solid day's %d", withLunch.Total(), without.Total())
+	}
+
+	// As a hard constraint the solid day is infeasible
+	s.LunchBreakHard = true
+	if v := s.hardViolations(week(solid, nil, nil, nil, nil), in); v.MissedLunches != 1 {
+		t.Errorf("hard lunch break missed lunches = %d, want 1", v.MissedLunches)
+	}
+
+	// Repair puts the lunch break back into the first slot of the window
+	if got := s.insertLunchBreak(solid.Clone()); len(got) != 9 || !got[3].IsEmpty() || s.missesLunch(got) {
+		t.Errorf("day after inserting a lunch break = %v, want slot 4 free", got)
+	}
+}
@@ -0,0 +1,90 @@
+// core/solver/placement.go
+package solver
+
+import (
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+/* Placement regions
+Edges and center are computed relative to the actual length of each day rather than a fixed
+number of slots, so they stay meaningful once days have different lengths.
+Edges:  the first and the last slot of the day.
+Center: the middle third of the day, which always contains the exact middle slot(s),
+        on days with one or two slots every slot is both an edge and the center.
+*/
+
+// centerRegion returns the first and last slot of the middle region of a day with dayLen slots.
+func centerRegion(dayLen int) (lo, hi int) {
+	return dayLen / 3, dayLen - 1 - dayLen/3
+}
+
+// isEdgeSlot reports whether the slot is the first or the last one of the day.
+func isEdgeSlot(slot, dayLen int) bool {
+	return slot == 0 || slot == dayLen-1
+}
+
+// isCenterSlot reports whether the slot lies in the middle region of the day.
+func isCenterSlot(slot, dayLen int) bool {
+	lo, hi := centerRegion(dayLen)
+	return slot >= lo && slot <= hi
+}
+
+// placementDistance returns how many slots away the slot is from the region requested
+// by the placement on a day with dayLen slots, 0 means the placement is satisfied.
+func placementDistance(placement input.SubjectPlacementType, slot, dayLen int) int {
+	switch placement {
+	case input.SubjectPlacementEdges:
+		return min(slot, dayLen-1-slot)
+	case input.SubjectPlacementCenter:
+		lo, hi := centerRegion(dayLen)
+		if slot < lo {
+			return lo - slot
+		}
+		if slot > hi {
+			return slot - hi
+		}
+	}
+	return 0
+}
+
+// The consecutive subjects groups of a single chunk placed by randomIndividual
+type placedChunk struct {
+	placement  input.SubjectPlacementType
+	difficulty int // The difficulty of the chunk's global subject, see subjectDifficulty
+	groups     []output.SubjectsGroup
+}
+
+// arrangeByPlacement orders the chunks of a day by their placement, edge chunks take turns
+// at the start and the end of the day, center chunks sit in the middle and the remaining
+// chunks fill the space in between, the most difficult ones first, chunks of the same kind
+// and difficulty keep their order.
+func arrangeByPlacement(chunks []placedChunk) []output.SubjectsGroup {
+	var front, back, center, rest []placedChunk
+	for _, c := range chunks {
+		switch c.placement {
+		case input.SubjectPlacementEdges:
+			if len(front) <= len(back) {
+				front = append(front, c)
+			} else {
+				back = append([]placedChunk{c}, back...)
+			}
+		case input.SubjectPlacementCenter:
+			center = append(center, c)
+		default:
+			rest = append(rest, c)
+		}
+	}
+
+	slices.SortStableFunc(rest, func(a, b placedChunk) int { return b.difficulty - a.difficulty })
+	half := len(rest) / 2
+	var day []output.SubjectsGroup
+	for _, part := range [][]placedChunk{front, rest[:half], center, rest[half:], back} {
+		for _, c := range part {
+			day = append(day, c.groups...)
+		}
+	}
+	return day
+}
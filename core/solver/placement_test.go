@@ -0,0 +1,92 @@
+// core/solver/placement_test.go
+package solver
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestPlacementRegions(t *testing.T) {
+	tests := []struct {
+		dayLen        int
+		edges, center []int
+	}{
+		{1, []int{0}, []int{0}},
+		{2, []int{0, 1}, []int{0, 1}},
+		{3, []int{0, 2}, []int{1}},
+		{5, []int{0, 4}, []int{1, 2, 3}},
+		{6, []int{0, 5}, []int{2, 3}},
+		{9, []int{0, 8}, []int{3, 4, 5}},
+	}
+	for _, tt := range tests {
+		var edges, center []int
+		for slot := range tt.dayLen {
+			if isEdgeSlot(slot, tt.dayLen) {
+				edges = append(edges, slot)
+			}
+			if isCenterSlot(slot, tt.dayLen) {
+				center = append(center, slot)
+			}
+		}
+		if !slices.Equal(edges, tt.edges) {
+			t.Errorf("%d slots: edges = %v, want %v", tt.dayLen, edges, tt.edges)
+		}
+		if !slices.Equal(center, tt.center) {
+			t.Errorf("%d slots: center = %v, want %v", tt.dayLen, center, tt.center)
+		}
+	}
+}
+
+func TestPlacementPenalty(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &a, Allocation: input.Allocation{1}, Placement: input.SubjectPlacementEdges},
+		{GlobalSubject: &b, Allocation: input.Allocation{4}},
+	}}}}
+	s := &Solver{}
+
+	atEdge := week(lessons(&a, &b, &b, &b, &b), nil, nil, nil, nil)
+	inMiddle := week(lessons(&b, &b, &a, &b, &b), nil, nil, nil, nil)
+	if p := s.placementPenalty(atEdge, in); p != 0 {
+		t.Errorf("edge placement penalty = %d, want 0", p)
+	}
+	if p := s.placementPenalty(inMiddle, in); p != 2*DefaultFitnessWeights.Placement {
+		t.Errorf("middle placement penalty = %d, want 2 slots of %d", p, DefaultFitnessWeights.Placement)
+	}
+	if s.fitness(atEdge, in) >= s.fitness(inMiddle, in) {
+		t.Error("edge subject at slot 0 doesn't score better than in the middle")
+	}
+}
+
+func TestInitialPlacement(t *testing.T) {
+	e, a, b, c := input.GlobalSubject("e"), input.GlobalSubject("a"), input.GlobalSubject("b"), input.GlobalSubject("c")
+	daily := input.Allocation{1, 1, 1, 1, 1}
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &a, Allocation: daily},
+		{GlobalSubject: &e, Allocation: daily, Placement: input.SubjectPlacementEdges},
+		{GlobalSubject: &b, Allocation: daily},
+		{GlobalSubject: &c, Allocation: daily},
+	}}}}
+	s := &Solver{PopulationSize: 20}
+
+	// Placed at random an edge lesson would sit at a boundary of a 4 lesson day half the time
+	edge, total := 0, 0
+	for _, ind := range s.initializePopulation(rand.New(rand.NewSource(1)), in) {
+		for _, d := range ind.Timetables[0] {
+			for slot, sg := range d {
+				if len(sg) > 0 && *sg[0].GlobalSubject == e {
+					total++
+					if isEdgeSlot(slot, len(d)) {
+						edge++
+					}
+				}
+			}
+		}
+	}
+	if total == 0 || edge*10 < total*9 {
+		t.Errorf("%d of %d edge lessons start at a day boundary, want at least 90%%", edge, total)
+	}
+}
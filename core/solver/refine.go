@@ -0,0 +1,61 @@
+// core/solver/refine.go
+package solver
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+const (
+	// The number of moves tried by refine when Solver.RefineIterations isn't set
+	defaultRefineIterations = 2000
+	// The starting temperature of refine, a move 100 points worse is first accepted
+	// with a probability of about 1/e, the temperature falls linearly to 0
+	refineTemperature = 100.0
+)
+
+func (s *Solver) refineIterations() int {
+	if s.RefineIterations > 0 {
+		return s.RefineIterations
+	}
+	return defaultRefineIterations
+}
+
+// refine improves an individual by simulated annealing, it applies single moves (slot swaps
+// and moves of lessons between days) and accepts worse results with a probability that
+// cools down over the iterations, so it can leave local optima early on. The best individual
+// seen is returned, so the result is never worse than best.
+func (s *Solver) refine(rng *rand.Rand, best fitInd, in input.InputData) fitInd {
+	free := s.freeDays()
+	if len(free) == 0 || len(best.ind.Timetables) == 0 || best.optimal() {
+		return best
+	}
+
+	current := best
+	iterations := s.refineIterations()
+	for i := 0; i < iterations; i++ {
+		candidate := current.ind.Clone()
+		if rng.Intn(2) == 0 {
+			swapSlots(rng, &candidate, free)
+		} else {
+			moveLesson(rng, &candidate, free)
+		}
+		s.repair(&candidate)
+		next := s.score(candidate, in)
+
+		temperature := refineTemperature * float64(iterations-i) / float64(iterations)
+		if delta := next.fitness - current.fitness; delta <= 0 || rng.Float64() < math.Exp(-float64(delta)/temperature) {
+			current = next
+		}
+		if slices.Compare(current.scores, best.scores) < 0 {
+			best = current
+			if best.optimal() {
+				break
+			}
+		}
+	}
+	return best
+}
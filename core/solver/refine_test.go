@@ -0,0 +1,29 @@
+// core/solver/refine_test.go
+package solver
+
+import (
+	"math/rand"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestRefineNeverWorse(t *testing.T) {
+	in := input.ExampleInputData
+	s := &Solver{PopulationSize: 5, RefineIterations: 200}
+	for seed := int64(1); seed <= 5; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		start := s.score(s.initializePopulation(rng, in)[0], in)
+
+		refined := s.refine(rng, start, in)
+		if refined.fitness > start.fitness {
+			t.Errorf("seed %d: refined fitness = %d, worse than the starting %d", seed, refined.fitness, start.fitness)
+		}
+		if got := s.fitness(refined.ind, in); got != refined.fitness {
+			t.Errorf("seed %d: refined individual scores %d, but was returned with %d", seed, got, refined.fitness)
+		}
+		if got := s.fitness(start.ind, in); got != start.fitness {
+			t.Errorf("seed %d: refine changed its input, it now scores %d instead of %d", seed, got, start.fitness)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// core/solver/report.go
+package solver
+
+import (
+	"time"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+// A machine-readable summary of a solve, e.g. to track the quality of the solver over time
+type SolveReport struct {
+	Generations int           `json:"generations"` // The number of generations that were run
+	Fitness     int           `json:"fitness"`     // The fitness of the best individual
+	Optimal     bool          `json:"optimal"`     // Whether every constraint is satisfied
+	Stop        StopReason    `json:"stop"`
+	Seed        int64         `json:"seed"`
+	Duration    time.Duration `json:"duration_ns"` // The wall-clock duration of the solve
+	// The best fitness after every generation, its last value is the fitness unless
+	// the best individual was refined after the last generation
+	History []int `json:"history"`
+}
+
+// ReportDetails evaluates a solved timetable for output.WriteReportHTML, with the fitness
+// breakdown and the hard constraint violations of Violations.
+func (s *Solver) ReportDetails(out output.OutputData, in input.InputData) *output.ReportDetails {
+	b := s.Evaluate(Individual{Timetables: out.DivisionsTimetables}, in)
+	return &output.ReportDetails{
+		Hard:       b.Hard(),
+		Soft:       b.Soft(),
+		Terms:      b.Terms(),
+		Violations: s.Violations(out, in),
+	}
+}
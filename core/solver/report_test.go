@@ -0,0 +1,73 @@
+// core/solver/report_test.go
+package solver
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+)
+
+func TestSolveReport(t *testing.T) {
+	s := &Solver{PopulationSize: 20, Generations: 30, MutationRate: 0.2, Seed: 4}
+	res := s.SolveContext(context.Background(), input.ExampleInputData)
+	report := res.Report
+
+	if report.Generations != res.Generations || len(report.History) != report.Generations {
+		t.Fatalf("history has %d values for %d generations, the result ran %d", len(report.History), report.Generations, res.Generations)
+	}
+	if last := report.History[len(report.History)-1]; last != report.Fitness || report.Fitness != res.Output.Fitness {
+		t.Errorf("last history value = %d and reported fitness = %d, want the result's %d", last, report.Fitness, res.Output.Fitness)
+	}
+	if report.Optimal != (report.Fitness == 0) || report.Seed != 4 || report.Duration <= 0 {
+		t.Errorf("report = %+v, want it optimal only at 0, seed 4 and a positive duration", report)
+	}
+
+	// The report is what main prints, so it has to round trip
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded SolveReport
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.History) != len(report.History) || decoded.Duration != report.Duration {
+		t.Errorf("decoded report = %+v (%v), want %+v", decoded, err, report)
+	}
+}
+
+func TestReportDetails(t *testing.T) {
+	s := &Solver{PopulationSize: 20, Generations: 30, MutationRate: 0.2, Seed: 4}
+	res := s.SolveContext(context.Background(), input.ExampleInputData)
+	details := s.ReportDetails(res.Output, input.ExampleInputData)
+
+	if details.Hard+details.Soft != res.Output.Fitness {
+		t.Errorf("hard %d + soft %d, want the fitness %d", details.Hard, details.Soft, res.Output.Fitness)
+	}
+	hard, soft := 0, 0
+	for _, term := range details.Terms {
+		if term.Hard {
+			hard += term.Penalty
+		} else {
+			soft += term.Penalty
+		}
+	}
+	if hard != details.Hard || soft != details.Soft {
+		t.Errorf("terms sum to hard %d and soft %d, want %d and %d", hard, soft, details.Hard, details.Soft)
+	}
+	if want := s.Violations(res.Output, input.ExampleInputData); !slices.Equal(details.Violations, want) {
+		t.Errorf("violations = %q, want %q", details.Violations, want)
+	}
+
+	// Every field of the breakdown is a term, named by its JSON tag
+	typ := reflect.TypeOf(FitnessBreakdown{})
+	if len(details.Terms) != typ.NumField() {
+		t.Fatalf("%d terms for %d breakdown fields", len(details.Terms), typ.NumField())
+	}
+	for i, term := range details.Terms {
+		if tag := typ.Field(i).Tag.Get("json"); term.Name != tag {
+			t.Errorf("term %d is named %s, want %s", i, term.Name, tag)
+		}
+	}
+}
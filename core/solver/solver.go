@@ -2,77 +2,522 @@
 package solver
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"math"
 	"math/rand"
+	"runtime"
+	"slices"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"smuggr.xyz/arrango/common/models/input"
 	"smuggr.xyz/arrango/common/models/output"
 )
 
+// Determines how unbalanced day loads of a division are penalized
+type ImbalanceMode string
+
+const (
+	ImbalanceSquared   ImbalanceMode = "squared"   // Proportional to the squared deviations from the mean daily load
+	ImbalanceThreshold ImbalanceMode = "threshold" // Only when the busiest and the lightest day differ by more than 4 groups
+)
+
+// An objective scores an individual, lower is better and 0 is optimal
+type Objective func(ind Individual, in input.InputData) int
+
+// The parameters of a solve, solving never modifies them and every solve keeps its state to
+// itself, so a single solver, or copies of it with different parameters, may run any number
+// of solves at once, e.g. for a parameter sweep, callbacks are called from the solve's goroutines
 type Solver struct {
+	// The number of individuals of a generation, at least minPopulationSize so there are
+	// parents to pick from, New rejects smaller ones and solves report them in Result.Err
 	PopulationSize int
 	Generations    int
 	MutationRate   float64
+	// The maximum number of distinct subjects a division should have in a day,
+	// days exceeding it are penalized, 0 means no limit
+	MaxSubjectsPerDay int
+	// The seed of the random number generator, 0 means a time based seed
+	Seed int64
+	// Whether each generation uses its own generator seeded with Seed + generation,
+	// so a single generation can be reproduced regardless of the ones before it
+	SeedPerGeneration bool
+	// How unbalanced day loads are penalized, empty means ImbalanceSquared
+	ImbalanceMode ImbalanceMode
+	// The maximum number of goroutines a single solve may keep busy at once across all
+	// concurrent work (evaluation workers and islands), the goroutine calling the solve
+	// included, 0 means runtime.NumCPU()
+	MaxParallelism int
+	// The number of slots available in a day, used to check whether the input fits
+	// into a week, 0 means the length of the input's SlotSchedule, or input.MaxDailyHours without one
+	MaxSlotsPerDay int
+	// The number of days in a week, e.g. 6 for schools with Saturday classes,
+	// 0 means defaultDaysPerWeek
+	DaysPerWeek int
+	// Scores individuals instead of DefaultFitness, e.g. to add the rules of an institution
+	// on top of it, lower is better and 0 is optimal, nil means DefaultFitness
+	Fitness Objective
+	// Objectives in order of priority, compared lexicographically instead of the fitness,
+	// so an objective is only optimized among individuals that are equal in all objectives
+	// before it, e.g. []Objective{s.HardObjective, s.SoftObjective}, nil means the fitness
+	Objectives []Objective
+	// Days that are fixed across all divisions, indexed from 0 (Monday), they are seeded from
+	// FixedTimetables and never changed while the remaining days of the week evolve
+	FixedDays []int
+	// The already published timetables that the fixed days are taken from, indexed by the division index
+	FixedTimetables []output.Days
+	// Whether subjects are always placed in the first of their classrooms instead of a
+	// random one, useful for tests and for keeping classroom assignments frozen
+	DeterministicClassrooms bool
+	// How often a checkpoint of the running solve is passed to OnCheckpoint, in generations, 0 disables it
+	CheckpointInterval int
+	// Called with a checkpoint every CheckpointInterval generations, e.g. to write it to disk
+	// so the solve can be continued later with ResumeFrom
+	OnCheckpoint func(cp Checkpoint)
+	// Called after every generation with its index and the best fitness found so far,
+	// e.g. to log progress, nil means no callback, with several islands it's called
+	// after every migration instead
+	OnGeneration func(gen int, bestFitness int)
+	// The number of the fittest individuals deep-copied verbatim into the next generation,
+	// at least one is always kept so the best fitness never regresses
+	Elitism int
+	// The number of generations without an improvement of the best individual after
+	// which the solve stops early, 0 means it always runs all generations
+	StagnationLimit int
+	// The bounds of the adaptive mutation rate, it starts at the minimum, rises while the
+	// best individual stagnates and decays after improvements, when the bounds are equal
+	// (e.g. both 0) the constant MutationRate is used instead
+	MutationRateMin float64
+	MutationRateMax float64
+	// The penalties of the fitness terms, e.g. a copy of DefaultFitnessWeights with some of
+	// them changed, nil means DefaultFitnessWeights
+	Weights *FitnessWeights
+	// The share of mutations that move a lesson to another day instead of swapping two
+	// slots within a day, moves can rebalance days that swaps never change, 0 means only swaps
+	MoveMutationRate float64
+	// Whether the best individual of the GA is refined by simulated annealing afterwards,
+	// single moves that often remove the last few violations the GA stalls on
+	Refine bool
+	// The number of moves tried by the refinement, 0 means defaultRefineIterations
+	RefineIterations int
+	// The number of populations of PopulationSize individuals evolved side by side, the
+	// fittest individuals migrate between them every MigrationInterval generations, 0 or 1
+	// means a single population, checkpoints aren't written with several islands
+	Islands int
+	// The number of generations between migrations, 0 means defaultMigrationInterval
+	MigrationInterval int
+	// The first and last slot of the window in which every division day needs a free slot
+	// for lunch, days with lessons in every slot of it are penalized, both 0 disables it
+	LunchWindowStart int
+	LunchWindowEnd   int
+	// Whether a day without a lunch break is a hard violation instead of a soft penalty,
+	// both use the LunchBreak weight
+	LunchBreakHard bool
+
+	// When the generations of a SolveWithBudget stop, zero means Generations are run
+	deadline time.Time
 }
 
 type Individual struct {
-	Timetables []output.Days // One timetable per division
+	Timetables []output.Days `json:"timetables"` // One timetable per division
 }
 
+// Solve solves like SolveContext without a deadline and returns only the timetables, they're
+// empty when the solver is invalid, use SolveContext to see why.
 func (s *Solver) Solve(in input.InputData) output.OutputData {
-	rand.Seed(time.Now().UnixNano())
-
-	pop := s.initializePopulation(in)
-
-	bestIndividual := pop[0]
-	bestFitness := s.fitness(bestIndividual, in)
-
-	for g := 0; g < s.Generations; g++ {
-		type fitInd struct {
-			ind     Individual
-			fitness int
-		}
-		fits := make([]fitInd, len(pop))
-		for i, ind := range pop {
-			f := s.fitness(ind, in)
-			fits[i] = fitInd{ind, f}
-			if f < bestFitness {
-				bestFitness = f
-				bestIndividual = ind
-				if bestFitness == 0 {
-					break
-				}
-			}
+	return s.SolveContext(context.Background(), in).Output
+}
+
+// Why a solve stopped
+type StopReason string
+
+const (
+	StopGenerations StopReason = "generations" // All generations were run
+	StopOptimal     StopReason = "optimal"     // An optimal individual was found
+	StopCancelled   StopReason = "cancelled"   // The context was cancelled or its deadline passed
+	StopStagnated   StopReason = "stagnated"   // The best individual didn't improve for StagnationLimit generations
+	StopBudget      StopReason = "budget"      // The time budget of SolveWithBudget was spent
+)
+
+type Result struct {
+	Output      output.OutputData
+	Stop        StopReason
+	Generations int   // The number of generations that were run
+	Seed        int64 // The seed that was used, pass it back as Solver.Seed to reproduce the run
+	// The hard constraint violations left in the timetables, see Violations, empty when they're feasible
+	Violations []string
+	// The number of migrations between islands, 0 with a single island
+	Migrations int
+	Report     SolveReport
+	// Why the solve couldn't run, e.g. a PopulationSize below minPopulationSize, the output
+	// then holds an empty timetable per division, nil when it ran
+	Err error
+}
+
+// SolveContext solves like Solve, but stops early and returns the best timetables found
+// so far when the context is cancelled or its deadline passes.
+func (s *Solver) SolveContext(ctx context.Context, in input.InputData) Result {
+	if err := s.checkPopulation(); err != nil {
+		return Result{
+			Output: output.OutputData{DivisionsTimetables: make([]output.Days, len(in.Divisions))},
+			Err:    err,
 		}
+	}
+
+	start := time.Now()
+	var st *solveState
+	migrations := 0
+	if s.islands() > 1 {
+		st, migrations = s.runIslands(ctx, in)
+	} else {
+		st = s.newState(in)
+		s.run(ctx, st, in)
+	}
+	if s.Refine && ctx.Err() == nil && s.deadline.IsZero() {
+		st.best = s.refine(st.rng, st.best, in)
+	}
+	result := st.result()
+	result.Migrations = migrations
+	result.Report = SolveReport{
+		Generations: result.Generations,
+		Fitness:     result.Output.Fitness,
+		Optimal:     st.best.optimal(),
+		Stop:        result.Stop,
+		Seed:        result.Seed,
+		Duration:    time.Since(start),
+		History:     st.history,
+	}
+	if result.Output.Fitness != 0 {
+		result.Violations = s.Violations(result.Output, in)
+	}
+	return result
+}
 
-		if bestFitness == 0 {
+// SolveWithBudget solves like Solve, but ignores Generations and evolves the population until
+// the budget is spent, an optimal individual is found or the population stagnates, and returns
+// the best timetables found, the generation that's running when the budget runs out is finished
+// first, the whole budget goes to the generations, so Refine is ignored.
+func (s *Solver) SolveWithBudget(in input.InputData, budget time.Duration) Result {
+	budgeted := *s
+	budgeted.Generations = math.MaxInt
+	budgeted.deadline = time.Now().Add(budget)
+	return budgeted.SolveContext(context.Background(), in)
+}
+
+// more reports whether the generation is run, it's before the last one and, with a time
+// budget, before the deadline.
+func (s *Solver) more(generation int) bool {
+	return generation < s.Generations && !s.budgetSpent()
+}
+
+// budgetSpent reports whether the deadline of a SolveWithBudget has passed.
+func (s *Solver) budgetSpent() bool {
+	return !s.deadline.IsZero() && !time.Now().Before(s.deadline)
+}
+
+// An individual of the final population along with its fitness
+type RankedIndividual struct {
+	Individual
+	Fitness int `json:"fitness"`
+}
+
+// SolveAll solves like Solve, but returns the entire final population sorted from the fittest
+// individual, e.g. to study the distribution of the solutions the algorithm converges to,
+// it's empty when the solver is invalid, see Result.Err.
+func (s *Solver) SolveAll(in input.InputData) []RankedIndividual {
+	fits := s.solveRanked(in)
+	ranked := make([]RankedIndividual, len(fits))
+	for i, f := range fits {
+		ranked[i] = RankedIndividual{Individual: f.ind, Fitness: f.fitness}
+	}
+	return ranked
+}
+
+// SolveTopK solves like Solve, but returns up to k distinct timetables of the final population
+// sorted from the fittest, so a planner can pick between alternatives by hand. The population
+// tends to converge to clones, so fewer than k are returned when there aren't k distinct ones,
+// none are returned when the solver is invalid, see Result.Err.
+func (s *Solver) SolveTopK(in input.InputData, k int) []output.OutputData {
+	var top []output.OutputData
+	seen := make(map[uint64]bool)
+	for _, f := range s.solveRanked(in) {
+		if len(top) >= k {
 			break
 		}
+		h := hashIndividual(f.ind)
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		top = append(top, output.OutputData{DivisionsTimetables: f.ind.Timetables, Fitness: f.fitness})
+	}
+	return top
+}
+
+// solveRanked runs a solve and returns the final population sorted from the fittest individual,
+// or nothing when the solver is invalid.
+func (s *Solver) solveRanked(in input.InputData) []fitInd {
+	if s.checkPopulation() != nil {
+		return nil
+	}
+	st := s.newState(in)
+	s.run(context.Background(), st, in)
+
+	fits, _ := s.evaluate(st.pop, in, st.cache)
+	sort.SliceStable(fits, func(i, j int) bool {
+		return slices.Compare(fits[i].scores, fits[j].scores) < 0
+	})
+	return fits
+}
+
+// The number of time based seeds handed out so far
+var seedCount atomic.Int64
+
+// randomSeed returns a time based seed, solves started at the same time still get
+// different ones, so concurrent solves without a seed never run the same search.
+func randomSeed() int64 {
+	return time.Now().UnixNano() + seedCount.Add(1)*islandSeedStride
+}
+
+// newState initializes a solve with a random population.
+func (s *Solver) newState(in input.InputData) *solveState {
+	seed := s.Seed
+	if seed == 0 {
+		seed = randomSeed()
+	}
+	src := newCountingSource(seed, 0)
+	rng := rand.New(src)
+
+	pop := s.initializePopulation(rng, in)
+
+	return &solveState{
+		seed: seed,
+		src:  src,
+		rng:  rng,
+		rate: s.initialMutationRate(),
+		pop:  pop,
+		best: s.score(pop[0], in),
+	}
+}
+
+// The state of a running solve, everything that's needed to continue it
+type solveState struct {
+	generation int // The generation that is run next
+	seed       int64
+	src        *countingSource
+	rng        *rand.Rand
+	pop        []Individual
+	best       fitInd
+	stale      int     // The number of generations since the best individual last improved
+	rate       float64 // The current mutation rate
+	cache      fitnessCache
+	stop       StopReason
+	history    []int // The best fitness after every generation
+}
+
+func (st *solveState) result() Result {
+	return Result{
+		Output: output.OutputData{
+			DivisionsTimetables: st.best.ind.Timetables,
+			Fitness:             st.best.fitness,
+		},
+		Stop:        st.stop,
+		Generations: st.generation,
+		Seed:        st.seed,
+	}
+}
+
+// run evolves the population from the state's generation until the last generation or the
+// deadline of a time budget, until an optimal individual is found or until the context is done.
+func (s *Solver) run(ctx context.Context, st *solveState, in input.InputData) {
+	st.stop = StopGenerations
+	for ; s.more(st.generation); st.generation++ {
+		if ctx.Err() != nil {
+			st.stop = StopCancelled
+			return
+		}
+
+		if s.OnCheckpoint != nil && s.CheckpointInterval > 0 &&
+			st.generation > 0 && st.generation%s.CheckpointInterval == 0 {
+			s.OnCheckpoint(st.checkpoint())
+		}
+
+		rng := st.rng
+		if s.SeedPerGeneration {
+			rng = s.generationRand(st.seed, st.generation)
+		}
+
+		var fits []fitInd
+		fits, st.cache = s.evaluate(st.pop, in, st.cache)
+
+		// Reduce after the whole population is evaluated, so the result doesn't depend
+		// on the order of evaluation, ties are resolved by the lower index
+		if genBest := bestOf(fits); slices.Compare(genBest.scores, st.best.scores) < 0 {
+			genBest.ind = genBest.ind.Clone()
+			st.best = genBest
+			st.stale = 0
+		} else {
+			st.stale++
+		}
+		st.rate = s.adaptMutationRate(st.rate, st.stale == 0)
+		st.history = append(st.history, st.best.fitness)
+		if s.OnGeneration != nil {
+			s.OnGeneration(st.generation, st.best.fitness)
+		}
+
+		if st.best.optimal() {
+			st.stop = StopOptimal
+			st.generation++
+			return
+		}
+
+		if s.StagnationLimit > 0 && st.stale >= s.StagnationLimit {
+			st.stop = StopStagnated
+			st.generation++
+			return
+		}
 
 		sort.Slice(fits, func(i, j int) bool {
-			return fits[i].fitness < fits[j].fitness
+			return slices.Compare(fits[i].scores, fits[j].scores) < 0
 		})
 
 		nextPop := make([]Individual, 0, s.PopulationSize)
-		// selection: top half
-		for i := 0; i < s.PopulationSize/2; i++ {
-			nextPop = append(nextPop, fits[i].ind)
+		// selection: the elite is copied verbatim, the rest of the top half survives
+		elite := min(max(s.Elitism, 1), len(fits))
+		for i := 0; i < max(elite, s.PopulationSize/2); i++ {
+			ind := fits[i].ind
+			if i < elite {
+				ind = ind.Clone()
+			}
+			nextPop = append(nextPop, ind)
 		}
 
 		// Reproduction
 		for len(nextPop) < s.PopulationSize {
-			p1 := fits[rand.Intn(s.PopulationSize/2)].ind
-			p2 := fits[rand.Intn(s.PopulationSize/2)].ind
-			child := s.crossover(p1, p2)
-			s.mutate(&child)
+			p1 := fits[rng.Intn(s.PopulationSize/2)].ind
+			p2 := fits[rng.Intn(s.PopulationSize/2)].ind
+			child := s.crossover(rng, p1, p2)
+			s.mutate(rng, &child, in, st.rate)
+			s.repairTeacherOverlap(rng, &child, st.rate)
+			s.repair(&child)
 			nextPop = append(nextPop, child)
 		}
 
-		pop = nextPop
+		st.pop = nextPop
+	}
+	if s.budgetSpent() {
+		st.stop = StopBudget
+	}
+}
+
+// Clone deep-copies the timetables of an individual, so changes to the copy's
+// days never affect the original.
+func (ind Individual) Clone() Individual {
+	clone := Individual{Timetables: make([]output.Days, len(ind.Timetables))}
+	for dIdx, days := range ind.Timetables {
+		clone.Timetables[dIdx] = days.Clone()
+	}
+	return clone
+}
+
+type fitInd struct {
+	ind     Individual
+	fitness int   // The fitness, or the score of the first objective when objectives are set
+	scores  []int // The scores of the objectives, or just the fitness
+}
+
+// optimal reports whether every objective is fully satisfied.
+func (f fitInd) optimal() bool {
+	for _, score := range f.scores {
+		if score != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluate computes the fitness of every individual of the population, in order, individuals
+// found in the cache aren't scored again, it returns the cache of the evaluated population.
+func (s *Solver) evaluate(pop []Individual, in input.InputData, cache fitnessCache) ([]fitInd, fitnessCache) {
+	fits := make([]fitInd, len(pop))
+	next := make(fitnessCache, len(pop))
+	hashes := make([]uint64, len(pop))
+	var misses []int
+	for i, ind := range pop {
+		hashes[i] = hashIndividual(ind)
+		if f, ok := cache[hashes[i]]; ok {
+			f.ind = ind
+			fits[i] = f
+		} else {
+			misses = append(misses, i)
+		}
+	}
+
+	s.scoreAll(pop, in, fits, misses)
+
+	for i, f := range fits {
+		next[hashes[i]] = f
+	}
+	return fits, next
+}
+
+// scoreAll scores the individuals of the population at the given indices into fits, the calling
+// goroutine scores along with the workers, so at most parallelism goroutines score at once.
+func (s *Solver) scoreAll(pop []Individual, in input.InputData, fits []fitInd, indices []int) {
+	workers := min(s.parallelism(), len(indices))
+	if workers <= 1 {
+		for _, i := range indices {
+			fits[i] = s.score(pop[i], in)
+		}
+		return
+	}
+
+	// Scoring only reads the individual and the input, every worker writes to its
+	// own index, so the result is the same as the serial loop above
+	var next atomic.Int64
+	work := func() {
+		for k := int(next.Add(1)) - 1; k < len(indices); k = int(next.Add(1)) - 1 {
+			fits[indices[k]] = s.score(pop[indices[k]], in)
+		}
 	}
+	var wg sync.WaitGroup
+	for range workers - 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			work()
+		}()
+	}
+	work()
+	wg.Wait()
+}
 
-	return output.OutputData{DivisionsTimetables: bestIndividual.Timetables}
+func (s *Solver) score(ind Individual, in input.InputData) fitInd {
+	if len(s.Objectives) == 0 {
+		f := s.fitness(ind, in)
+		return fitInd{ind: ind, fitness: f, scores: []int{f}}
+	}
+
+	scores := make([]int, len(s.Objectives))
+	for i, objective := range s.Objectives {
+		scores[i] = objective(ind, in)
+	}
+	return fitInd{ind: ind, fitness: scores[0], scores: scores}
+}
+
+// bestOf returns the fittest individual, the first one in case of a tie.
+func bestOf(fits []fitInd) fitInd {
+	best := fits[0]
+	for _, f := range fits[1:] {
+		if slices.Compare(f.scores, best.scores) < 0 {
+			best = f
+		}
+	}
+	return best
 }
 
 // Extract chunks of subject allocations
@@ -96,41 +541,163 @@ func (s *Solver) extractSubjectChunks(div input.Division) []subjectChunk {
 	return chunks
 }
 
-func (s *Solver) pickClassroom(subj input.Subject) *input.Classroom {
-	if len(subj.Classrooms) > 0 {
-		return subj.Classrooms[rand.Intn(len(subj.Classrooms))]
+// parallelism returns the concurrency limit of a single solve.
+func (s *Solver) parallelism() int {
+	if s.MaxParallelism > 0 {
+		return s.MaxParallelism
+	}
+	return runtime.NumCPU()
+}
+
+// generationRand returns the generator used by a single generation when SeedPerGeneration is set.
+func (s *Solver) generationRand(seed int64, generation int) *rand.Rand {
+	return rand.New(rand.NewSource(seed + int64(generation)))
+}
+
+// pickClassroom picks one of the subject's preferred classrooms, or now and then any of its
+// classrooms, so the population has room to resolve conflicts with a less suitable room.
+func (s *Solver) pickClassroom(rng *rand.Rand, subj input.Subject) *input.Classroom {
+	rooms := subj.Classrooms
+	if len(subj.PreferredClassrooms) > 0 {
+		if s.DeterministicClassrooms || len(subj.Classrooms) == 0 || rng.Float64() >= offPreferredClassroomRate {
+			rooms = subj.PreferredClassrooms
+		}
+	}
+	if len(rooms) > 0 && s.DeterministicClassrooms {
+		return rooms[0]
+	}
+	if len(rooms) > 0 {
+		return rooms[rng.Intn(len(rooms))]
 	}
 	return nil
 }
 
+// pickExtraClassrooms reserves the classrooms a subject needs besides the first one, distinct
+// from it and from each other, its preferred classrooms come before the others, in a random
+// order unless DeterministicClassrooms is set, fewer are reserved when the subject lists too few.
+func (s *Solver) pickExtraClassrooms(rng *rand.Rand, subj input.Subject, first *input.Classroom) []*input.Classroom {
+	if subj.RoomsNeeded < 2 {
+		return nil
+	}
+
+	preferred, others := slices.Clone(subj.PreferredClassrooms), slices.Clone(subj.Classrooms)
+	if !s.DeterministicClassrooms {
+		rng.Shuffle(len(preferred), func(i, j int) { preferred[i], preferred[j] = preferred[j], preferred[i] })
+		rng.Shuffle(len(others), func(i, j int) { others[i], others[j] = others[j], others[i] })
+	}
+
+	var extra []*input.Classroom
+	for _, c := range append(preferred, others...) {
+		if len(extra) == int(subj.RoomsNeeded)-1 {
+			break
+		}
+		if c == nil || (first != nil && c.Name == first.Name) || hasClassroom(extra, c) {
+			continue
+		}
+		extra = append(extra, c)
+	}
+	return extra
+}
+
 // Initialize a random individual with balanced day lengths for each division.
-func (s *Solver) randomIndividual(in input.InputData) Individual {
+func (s *Solver) randomIndividual(rng *rand.Rand, in input.InputData) Individual {
 	timetables := make([]output.Days, len(in.Divisions))
 
 	for dIdx, div := range in.Divisions {
 		// We start with empty days
-		var divisionDays output.Days
-		for i := 0; i < 5; i++ {
+		divisionDays := make(output.Days, s.daysPerWeek())
+		for i := range divisionDays {
 			divisionDays[i] = make([]output.SubjectsGroup, 0)
 		}
 
+		// Fixed days are taken as they are, the hours they already contain aren't placed again
+		type subjectKey struct {
+			globalSubject input.GlobalSubject
+			teacher       input.Teacher
+		}
+		fixedHours := make(map[subjectKey]uint)
+		for _, day := range s.FixedDays {
+			if day < 0 || day >= len(divisionDays) || dIdx >= len(s.FixedTimetables) || day >= len(s.FixedTimetables[dIdx]) {
+				continue
+			}
+			divisionDays[day] = s.FixedTimetables[dIdx][day].Clone()
+			for _, sg := range divisionDays[day] {
+				for _, subj := range sg {
+					if subj.GlobalSubject != nil {
+						fixedHours[subjectKey{deref(subj.GlobalSubject), deref(subj.Teacher)}]++
+					}
+				}
+			}
+		}
+
 		requiredChunks := s.extractSubjectChunks(div)
 
 		// Place chunks in the day with the fewest groups so far, to keep balanced
+		block := uint(0)
+		dayChunks := make([][]placedChunk, len(divisionDays))
 		for _, chunk := range requiredChunks {
+			key := subjectKey{deref(chunk.subj.GlobalSubject), deref(chunk.subj.Teacher)}
+			if fixedHours[key] >= chunk.size {
+				fixedHours[key] -= chunk.size
+				continue
+			}
+
 			// We need to place 'chunk.size' consecutive hours for the subject
 			// Pick a day that currently has the least number of groups
-			dayIdx := s.pickLeastLoadedDay(divisionDays)
-			// Append chunk.size groups with this subject
+			dayIdx := s.pickLeastLoadedDay(divisionDays, div, chunk)
+			// Hours of a multi-hour chunk are tagged, so splitting them up can be detected
+			var chunkBlock uint
+			if chunk.size > 1 {
+				block++
+				chunkBlock = block
+			}
+			// Append chunk.size groups with this subject, each in its own subjects group,
+			// so whole division subjects never get parallel siblings
+			placed := placedChunk{
+				placement:  chunk.subj.Placement,
+				difficulty: subjectDifficulty(in.GlobalSubjectInfo[deref(chunk.subj.GlobalSubject)]),
+			}
 			for i := uint(0); i < chunk.size; i++ {
-				sg := output.SubjectsGroup{}
-				sg[0] = output.Subject{
-					GlobalSubject: chunk.subj.GlobalSubject,
-					Teacher:       chunk.subj.Teacher,
-					Classroom:     s.pickClassroom(chunk.subj),
-					Group:         &chunk.subj.Group,
-				}
+				classroom := s.pickClassroom(rng, chunk.subj)
+				sg := output.SubjectsGroup{{
+					GlobalSubject:   chunk.subj.GlobalSubject,
+					Teacher:         chunk.subj.Teacher,
+					Classroom:       classroom,
+					Group:           &chunk.subj.Group,
+					Block:           chunkBlock,
+					ExtraClassrooms: s.pickExtraClassrooms(rng, chunk.subj, classroom),
+				}}
 				divisionDays[dayIdx] = append(divisionDays[dayIdx], sg)
+				placed.groups = append(placed.groups, sg)
+			}
+			dayChunks[dayIdx] = append(dayChunks[dayIdx], placed)
+		}
+
+		// Order the chunks of every day by their placement hints, so the population
+		// doesn't start with edge and center subjects in random positions
+		for day, chunks := range dayChunks {
+			placedGroups := 0
+			for _, c := range chunks {
+				placedGroups += len(c.groups)
+			}
+			before := divisionDays[day][:len(divisionDays[day])-placedGroups]
+			divisionDays[day] = append(before, arrangeByPlacement(chunks)...)
+		}
+
+		// Keep the slots before the division's earliest start empty
+		if div.EarliestStart > 0 {
+			for day := range divisionDays {
+				if len(divisionDays[day]) > 0 && !s.isFixedDay(day) {
+					padding := make([]output.SubjectsGroup, div.EarliestStart)
+					divisionDays[day] = append(padding, divisionDays[day]...)
+				}
+			}
+		}
+
+		// Leave a free slot for lunch
+		for day := range divisionDays {
+			if !s.isFixedDay(day) {
+				divisionDays[day] = s.insertLunchBreak(divisionDays[day])
 			}
 		}
 
@@ -140,160 +707,640 @@ func (s *Solver) randomIndividual(in input.InputData) Individual {
 	return Individual{Timetables: timetables}
 }
 
-// pickLeastLoadedDay returns the index of the day with the fewest subjects groups
-func (s *Solver) pickLeastLoadedDay(days output.Days) int {
-	minLoad := len(days[0])
-	minDay := 0
-	for i := 1; i < 5; i++ {
-		if len(days[i]) < minLoad {
-			minLoad = len(days[i])
-			minDay = i
+// sharesWholeDivisionSlot reports whether a subject that must be taught to the
+// whole division shares the subjects group with any other subject.
+func sharesWholeDivisionSlot(div input.Division, sg output.SubjectsGroup) bool {
+	placed := 0
+	whole := false
+	for _, p := range sg {
+		if p.GlobalSubject == nil {
+			continue
+		}
+		placed++
+		for _, subj := range div.Subjects {
+			if subj.WholeDivision && placedAs(p, subj) {
+				whole = true
+			}
+		}
+	}
+	return whole && placed > 1
+}
+
+// capacityOverflows counts the hours of a division taught to more students than fit into
+// the classroom, capacities of the top level classrooms take precedence over the placed ones.
+func capacityOverflows(days output.Days, div input.Division, capacities map[string]uint) int {
+	overflows := 0
+	for _, d := range days {
+		for _, sg := range d {
+			for _, placed := range sg {
+				if placed.GlobalSubject == nil || placed.Classroom == nil {
+					continue
+				}
+				capacity, ok := capacities[placed.Classroom.Name]
+				if !ok {
+					capacity = placed.Classroom.Capacity
+				}
+				if capacity == 0 {
+					continue
+				}
+
+				students := div.Students
+				for _, subj := range div.Subjects {
+					if subj.Students > 0 && placedAs(placed, subj) {
+						students = subj.Students
+						break
+					}
+				}
+				if students > capacity {
+					overflows++
+				}
+			}
+		}
+	}
+	return overflows
+}
+
+// divisionGaps counts the empty subjects groups that have lessons both before and after them,
+// a single one of them within the lunch window is a lunch break rather than a gap.
+func (s *Solver) divisionGaps(day output.Day) int {
+	first, last := -1, -1
+	for slot, sg := range day {
+		if !sg.IsEmpty() {
+			if first < 0 {
+				first = slot
+			}
+			last = slot
+		}
+	}
+
+	gaps, lunch := 0, false
+	for slot := first + 1; slot < last; slot++ {
+		if day[slot].IsEmpty() {
+			if !lunch && s.inLunchWindow(slot) {
+				lunch = true
+				continue
+			}
+			gaps++
+		}
+	}
+	return gaps
+}
+
+// splitBlocks counts the blocks whose hours aren't placed in consecutive slots of the day.
+func splitBlocks(day output.Day) int {
+	slots := make(map[uint][]int)
+	for slot, sg := range day {
+		for _, subj := range sg {
+			if subj.GlobalSubject != nil && subj.Block > 0 {
+				slots[subj.Block] = append(slots[subj.Block], slot)
+				break
+			}
+		}
+	}
+
+	split := 0
+	for _, s := range slots {
+		// Slots are appended in order, so a consecutive block spans exactly its length
+		if s[len(s)-1]-s[0]+1 != len(s) {
+			split++
+		}
+	}
+	return split
+}
+
+// unavailableSlots counts the hours of a division taught by a teacher in a slot
+// the teacher is unavailable in.
+func unavailableSlots(days output.Days, constraints map[input.Teacher]input.TeacherConstraints) int {
+	n := 0
+	for day, d := range days {
+		for slot, sg := range d {
+			for _, placed := range sg {
+				if placed.GlobalSubject == nil || placed.Teacher == nil {
+					continue
+				}
+				blocked := input.BlockedSlot{Day: day, Slot: slot}
+				if slices.Contains(constraints[*placed.Teacher].Unavailable, blocked) {
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
+
+// isFixedDay reports whether the day is one of the fixed days.
+func (s *Solver) isFixedDay(day int) bool {
+	return slices.Contains(s.FixedDays, day)
+}
+
+// freeDays returns the days that aren't fixed.
+func (s *Solver) freeDays() []int {
+	days := make([]int, 0, s.daysPerWeek())
+	for day := 0; day < s.daysPerWeek(); day++ {
+		if !s.isFixedDay(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// pickLeastLoadedDay returns the index of the free day with the fewest subjects groups for
+// the chunk, avoiding the days that are forbidden for the subject unless all of them are, the
+// days the chunk would push past the division's MaxSlotsPerDay, the days that already have
+// the subject unless there's no other, and then the days outside the subject's preferred days
+func (s *Solver) pickLeastLoadedDay(days output.Days, div input.Division, chunk subjectChunk) int {
+	free := s.freeDays()
+	if len(free) == 0 {
+		return 0
+	}
+
+	flag := func(b bool) int {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	var minRank []int
+	minDay := free[0]
+	for _, i := range free {
+		if i >= len(days) || slices.Contains(chunk.subj.ForbiddenDays, i) {
+			continue
+		}
+		full := div.MaxSlotsPerDay > 0 && int(div.EarliestStart)+lessonCount(days[i])+int(chunk.size) > int(div.MaxSlotsPerDay)
+		repeated := hasSubject(days[i], chunk.subj)
+		off := len(chunk.subj.PreferredDays) > 0 && !slices.Contains(chunk.subj.PreferredDays, i)
+		rank := []int{flag(full), flag(repeated), flag(off), len(days[i])}
+		if minRank == nil || slices.Compare(rank, minRank) < 0 {
+			minRank, minDay = rank, i
 		}
 	}
 	return minDay
 }
 
-func (s *Solver) initializePopulation(in input.InputData) []Individual {
+// lessonCount returns the number of slots of the day in which something is taught.
+func lessonCount(day output.Day) int {
+	n := 0
+	for _, sg := range day {
+		if !sg.IsEmpty() {
+			n++
+		}
+	}
+	return n
+}
+
+// hasSubject reports whether the subject is already placed in the day.
+func hasSubject(day output.Day, subj input.Subject) bool {
+	for _, sg := range day {
+		for _, placed := range sg {
+			if placed.GlobalSubject != nil && placedAs(placed, subj) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// The smallest population a solve evolves, the top half has to hold a parent
+const minPopulationSize = 2
+
+// checkPopulation reports a PopulationSize too small to pick parents from, New rejects it
+// already, but a Solver may be built by hand.
+func (s *Solver) checkPopulation() error {
+	if s.PopulationSize < minPopulationSize {
+		return fmt.Errorf("population size must be at least %d, got %d", minPopulationSize, s.PopulationSize)
+	}
+	return nil
+}
+
+func (s *Solver) initializePopulation(rng *rand.Rand, in input.InputData) []Individual {
 	pop := make([]Individual, s.PopulationSize)
-	for i := 0; i < s.PopulationSize; i++ {
-		pop[i] = s.randomIndividual(in)
+	for i := range pop {
+		pop[i] = s.randomIndividual(rng, in)
 	}
 	return pop
 }
 
-func (s *Solver) fitness(ind Individual, in input.InputData) int {
-	score := 0
+// HardViolations counts the hard constraint violations of a timetable.
+type HardViolations struct {
+	TeacherOverlaps   int
+	ClassroomOverlaps int
+	UnmetHours        int // Allocated hours that were not placed in the timetable
+	TeacherConflicts  int // Slots in which both teachers of a conflicting pair teach
+	ForbiddenDays     int // Hours placed on days that are forbidden for the subject
+	EarlyLessons      int // Lessons placed before the earliest start of their division
+	SharedWholeSlots  int // Slots in which a whole division subject is taught in parallel with other groups
+	ParallelClashes   int // Parallel groups reusing the teacher or classroom of another group in the same slot
+	CapacityOverflows int // Hours taught to more students than fit into the classroom
+	DivisionGaps      int // Empty slots with lessons both before and after them in a division's day
+	SplitBlocks       int // Multi-hour blocks whose hours aren't placed in consecutive slots
+	UnavailableSlots  int // Hours taught by a teacher in one of their unavailable slots
+	DuplicateGroups   int // Parallel entries of a slot taught to a group that already has a lesson in it
+	MissedLunches     int // Days booked solid through the lunch window, only counted when Solver.LunchBreakHard is set
+	LateLessons       int // Lessons placed after the last slot of their division's day
+	OverfullSlots     int // Parallel lessons of a slot beyond output.MaxParallelGroups
+}
+
+// Feasible reports whether no hard constraint is violated.
+func (v HardViolations) Feasible() bool {
+	return v.TeacherOverlaps == 0 && v.ClassroomOverlaps == 0 && v.UnmetHours == 0 &&
+		v.TeacherConflicts == 0 && v.ForbiddenDays == 0 && v.EarlyLessons == 0 &&
+		v.SharedWholeSlots == 0 && v.ParallelClashes == 0 &&
+		v.CapacityOverflows == 0 && v.DivisionGaps == 0 && v.SplitBlocks == 0 &&
+		v.UnavailableSlots == 0 && v.DuplicateGroups == 0 && v.MissedLunches == 0 &&
+		v.LateLessons == 0 && v.OverfullSlots == 0
+}
+
+func (v HardViolations) String() string {
+	return fmt.Sprintf("%d teacher overlaps, %d classroom overlaps, %d unmet hours, %d teacher conflicts, %d hours on forbidden days, %d early lessons, %d shared whole division slots, %d parallel group clashes, %d capacity overflows, %d division gaps, %d split blocks, %d hours in unavailable slots, %d duplicate groups, %d days without a lunch break, %d late lessons, %d lessons in overfull slots",
+		v.TeacherOverlaps, v.ClassroomOverlaps, v.UnmetHours, v.TeacherConflicts, v.ForbiddenDays, v.EarlyLessons,
+		v.SharedWholeSlots, v.ParallelClashes, v.CapacityOverflows, v.DivisionGaps, v.SplitBlocks, v.UnavailableSlots,
+		v.DuplicateGroups, v.MissedLunches, v.LateLessons, v.OverfullSlots)
+}
+
+// plus returns the sum of both violation counts.
+func (v HardViolations) plus(o HardViolations) HardViolations {
+	return HardViolations{
+		TeacherOverlaps:   v.TeacherOverlaps + o.TeacherOverlaps,
+		ClassroomOverlaps: v.ClassroomOverlaps + o.ClassroomOverlaps,
+		UnmetHours:        v.UnmetHours + o.UnmetHours,
+		TeacherConflicts:  v.TeacherConflicts + o.TeacherConflicts,
+		ForbiddenDays:     v.ForbiddenDays + o.ForbiddenDays,
+		EarlyLessons:      v.EarlyLessons + o.EarlyLessons,
+		SharedWholeSlots:  v.SharedWholeSlots + o.SharedWholeSlots,
+		ParallelClashes:   v.ParallelClashes + o.ParallelClashes,
+		CapacityOverflows: v.CapacityOverflows + o.CapacityOverflows,
+		DivisionGaps:      v.DivisionGaps + o.DivisionGaps,
+		SplitBlocks:       v.SplitBlocks + o.SplitBlocks,
+		UnavailableSlots:  v.UnavailableSlots + o.UnavailableSlots,
+		DuplicateGroups:   v.DuplicateGroups + o.DuplicateGroups,
+		MissedLunches:     v.MissedLunches + o.MissedLunches,
+		LateLessons:       v.LateLessons + o.LateLessons,
+		OverfullSlots:     v.OverfullSlots + o.OverfullSlots,
+	}
+}
+
+func (s *Solver) hardViolations(ind Individual, in input.InputData) HardViolations {
+	usage := slotUsageOf(ind.Timetables)
+	v := overlapViolations(usage, in)
+	for dIdx := range in.Divisions {
+		v = v.plus(s.divisionViolations(ind, in, dIdx))
+	}
+	return v
+}
+
+// overlapViolations counts the violations shared between divisions, i.e. teachers and
+// classrooms used by more than one of them at the same time.
+func overlapViolations(usage slotUsage, in input.InputData) HardViolations {
+	var v HardViolations
 
 	// Check teacher/classroom overlaps
-	type slotKey struct {
-		day  int
-		slot int
+	for _, teachers := range usage.teachers {
+		for _, n := range teachers {
+			v.TeacherOverlaps += n - 1
+		}
+	}
+	for _, classrooms := range usage.classrooms {
+		for _, n := range classrooms {
+			v.ClassroomOverlaps += n - 1
+		}
 	}
-	teacherUsed := make(map[slotKey]map[input.Teacher]bool)
-	classroomUsed := make(map[slotKey]map[input.Classroom]bool)
 
-	for _, divTT := range ind.Timetables {
-		for day := 0; day < 5; day++ {
-			for slot, sg := range divTT[day] {
-				tk := slotKey{day: day, slot: slot}
-				for _, subj := range sg {
-					if subj.GlobalSubject == nil {
-						continue
-					}
-					if subj.Teacher != nil {
-						if teacherUsed[tk] == nil {
-							teacherUsed[tk] = make(map[input.Teacher]bool)
-						}
-						if teacherUsed[tk][*subj.Teacher] {
-							score += 1000 // Teacher overlap
-						} else {
-							teacherUsed[tk][*subj.Teacher] = true
-						}
-					}
-					if subj.Classroom != nil {
-						if classroomUsed[tk] == nil {
-							classroomUsed[tk] = make(map[input.Classroom]bool)
-						}
-						if classroomUsed[tk][*subj.Classroom] {
-							score += 1000 // Classroom overlap
-						} else {
-							classroomUsed[tk][*subj.Classroom] = true
-						}
-					}
-				}
+	// Check conflicting teachers aren't teaching at the same time
+	for _, pair := range in.TeacherConflicts {
+		for _, used := range usage.teachers {
+			if used[pair[0]] > 0 && used[pair[1]] > 0 {
+				v.TeacherConflicts++
 			}
 		}
 	}
 
+	return v
+}
+
+// divisionViolations counts the hard constraint violations within a single division's timetable.
+func (s *Solver) divisionViolations(ind Individual, in input.InputData, dIdx int) HardViolations {
+	var v HardViolations
+	div := in.Divisions[dIdx]
+
 	// Check allocations are met
-	for dIdx, div := range in.Divisions {
-		requiredChunks := s.extractSubjectChunks(div)
-		// Copy needed counts
-		remaining := make([]subjectChunk, len(requiredChunks))
-		copy(remaining, requiredChunks)
+	for _, c := range s.unmetChunks(ind.Timetables[dIdx], div) {
+		v.UnmetHours += int(c.size)
+	}
 
-		for day := 0; day < 5; day++ {
-			for _, sg := range ind.Timetables[dIdx][day] {
-				for _, subj := range sg {
-					if subj.GlobalSubject == nil {
-						continue
-					}
-					for i := range remaining {
-						if remaining[i].subj.GlobalSubject == subj.GlobalSubject &&
-							remaining[i].subj.Teacher == subj.Teacher {
-							// placed an hour
-							if remaining[i].size > 0 {
-								remaining[i].size--
-							}
-						}
-					}
+	// Check no lessons are placed before the division's earliest start
+	for day := range ind.Timetables[dIdx] {
+		for slot, sg := range ind.Timetables[dIdx][day] {
+			if slot >= int(div.EarliestStart) {
+				break
+			}
+			if !sg.IsEmpty() {
+				v.EarlyLessons++
+			}
+		}
+	}
+
+	// Check no lessons are placed after the division's last slot
+	if div.MaxSlotsPerDay > 0 {
+		for day := range ind.Timetables[dIdx] {
+			for slot := int(div.MaxSlotsPerDay); slot < len(ind.Timetables[dIdx][day]); slot++ {
+				if !ind.Timetables[dIdx][day][slot].IsEmpty() {
+					v.LateLessons++
 				}
 			}
 		}
+	}
 
-		// penalty for not meeting required allocations
-		for _, c := range remaining {
-			if c.size > 0 {
-				score += int(c.size) * 500
+	// Check whole division subjects aren't taught in parallel with other groups, that parallel
+	// groups don't share a teacher, a classroom or the group itself among themselves, and that
+	// no slot holds more groups than there are
+	for day := range ind.Timetables[dIdx] {
+		for _, sg := range ind.Timetables[dIdx][day] {
+			if sharesWholeDivisionSlot(div, sg) {
+				v.SharedWholeSlots++
 			}
+			v.ParallelClashes += parallelClashes(sg)
+			v.DuplicateGroups += duplicateGroups(sg)
+			v.OverfullSlots += overfullLessons(sg)
 		}
 	}
 
-	// No gaps in division timetables:
-	// Since we directly appended chunks, no "empty slots" were created.
-	// Each subjects group is consecutive. So no internal gaps by definition.
-	// If we considered gaps as missing groups, we would have introduced them ourselves.
-	// Hence no penalty needed here.
+	// Check there are no gaps in the division's days, mutation and crossover can leave
+	// an empty subjects group sandwiched between lessons
+	for day := range ind.Timetables[dIdx] {
+		v.DivisionGaps += s.divisionGaps(ind.Timetables[dIdx][day])
+	}
 
-	// Soft constraints: Unbalanced day distribution within a division
-	// Check difference in day loads (number of groups per day)
-	for dIdx := range ind.Timetables {
-		dayCounts := make([]int, 5)
-		for day := 0; day < 5; day++ {
-			dayCounts[day] = len(ind.Timetables[dIdx][day])
-		}
-		minC, maxC := dayCounts[0], dayCounts[0]
-		for _, c := range dayCounts[1:] {
-			if c < minC {
-				minC = c
+	// Check every day has a lunch break, when it's a hard constraint
+	if s.LunchBreakHard {
+		v.MissedLunches += s.missedLunches(ind.Timetables[dIdx])
+	}
+
+	// Check the hours of multi-hour blocks stay together, mutation can swap them apart
+	for day := range ind.Timetables[dIdx] {
+		v.SplitBlocks += splitBlocks(ind.Timetables[dIdx][day])
+	}
+
+	// Check the students fit into the classrooms
+	capacities := make(map[string]uint, len(in.Classrooms))
+	for _, c := range in.Classrooms {
+		capacities[c.Name] = c.Capacity
+	}
+	v.CapacityOverflows += capacityOverflows(ind.Timetables[dIdx], div, capacities)
+
+	// Check teachers aren't scheduled in their unavailable slots
+	v.UnavailableSlots += unavailableSlots(ind.Timetables[dIdx], in.TeacherConstraints)
+
+	// Check subjects aren't placed on their forbidden days
+	for _, subj := range div.Subjects {
+		for _, day := range subj.ForbiddenDays {
+			if day < 0 || day >= len(ind.Timetables[dIdx]) {
+				continue
 			}
-			if c > maxC {
-				maxC = c
+			for _, sg := range ind.Timetables[dIdx][day] {
+				for _, placed := range sg {
+					if placed.GlobalSubject != nil && placedAs(placed, subj) {
+						v.ForbiddenDays++
+					}
+				}
 			}
 		}
-		if maxC-minC > 4 {
-			score += (maxC - minC) * 5
+	}
+
+	return v
+}
+
+// unmetChunks returns the chunks of a division's allocation with the hours that
+// aren't placed in its timetable left as their size, every placed hour counts towards
+// a single chunk of its subject, an hour of a block towards a multi-hour chunk if one
+// is still unmet, so a dropped hour shows up even when the subject has hours on other days.
+func (s *Solver) unmetChunks(days output.Days, div input.Division) []subjectChunk {
+	requiredChunks := s.extractSubjectChunks(div)
+	// Copy needed counts
+	remaining := make([]subjectChunk, len(requiredChunks))
+	copy(remaining, requiredChunks)
+
+	for day := range days {
+		for _, sg := range days[day] {
+			for _, subj := range sg {
+				if subj.GlobalSubject == nil {
+					continue
+				}
+				// placed an hour, of the chunk of the same kind if there's one left
+				match := -1
+				for i := range remaining {
+					if remaining[i].size == 0 || !placedAs(subj, remaining[i].subj) {
+						continue
+					}
+					if (requiredChunks[i].size > 1) == (subj.Block > 0) {
+						match = i
+						break
+					}
+					if match < 0 {
+						match = i
+					}
+				}
+				if match >= 0 {
+					remaining[match].size--
+				}
+			}
 		}
 	}
+	return remaining
+}
+
+// Check re-evaluates the hard constraints of a solved timetable.
+func (s *Solver) Check(out output.OutputData, in input.InputData) HardViolations {
+	return s.hardViolations(Individual{Timetables: out.DivisionsTimetables}, in)
+}
+
+// WarnInfeasible writes a warning to w when the solved timetable has a nonzero fitness,
+// summarizing the remaining hard violations if there are any, it reports whether a warning
+// was written, so callers can exit with a failure whenever the fitness isn't 0.
+func (s *Solver) WarnInfeasible(w io.Writer, out output.OutputData, in input.InputData) bool {
+	if out.Fitness == 0 {
+		return false
+	}
+	v := s.Check(out, in)
+	if v.Feasible() {
+		fmt.Fprintf(w, "warning: timetable is feasible but not optimal (fitness %d)\n", out.Fitness)
+	} else {
+		fmt.Fprintf(w, "warning: timetable has unresolved conflicts (fitness %d): %s\n", out.Fitness, v)
+	}
+	return true
+}
+
+// DefaultFitness is the built-in fitness, the sum of the penalties of Evaluate, a custom
+// Solver.Fitness can wrap it to add terms of its own.
+func (s *Solver) DefaultFitness(ind Individual, in input.InputData) int {
+	return s.Evaluate(ind, in).Total()
+}
+
+// fitness scores an individual with Solver.Fitness, or with DefaultFitness when it isn't set.
+func (s *Solver) fitness(ind Individual, in input.InputData) int {
+	if s.Fitness != nil {
+		return s.Fitness(ind, in)
+	}
+	return s.DefaultFitness(ind, in)
+}
+
+// HardObjective scores the hard constraint violations of an individual, the violations
+// of each division are multiplied by its weight, overlaps between divisions by the
+// highest weight among the divisions involved.
+func (s *Solver) HardObjective(ind Individual, in input.InputData) int {
+	var b FitnessBreakdown
+	s.addHard(&b, ind, in)
+	return b.Hard()
+}
+
+// SoftObjective scores the soft constraint penalties of an individual.
+func (s *Solver) SoftObjective(ind Individual, in input.InputData) int {
+	var b FitnessBreakdown
+	s.addSoft(&b, ind, in)
+	return b.Soft()
+}
+
+func (s *Solver) mutate(rng *rand.Rand, ind *Individual, in input.InputData, rate float64) {
+	if rng.Float64() > rate {
+		return
+	}
+	if rng.Float64() < allocationMutationRate && s.fixAllocation(rng, ind, in) {
+		return
+	}
+	// Randomly pick a division/day and swap two slots if possible, there's nothing to
+	// rearrange without divisions or free days
+	free := s.freeDays()
+	if len(free) == 0 || len(ind.Timetables) == 0 {
+		return
+	}
+	if s.MoveMutationRate > 0 && rng.Float64() < s.MoveMutationRate {
+		moveLesson(rng, ind, free)
+		return
+	}
+	swapSlots(rng, ind, free)
+}
 
-	return score
+// swapSlots swaps two random slots of a random division's free day.
+func swapSlots(rng *rand.Rand, ind *Individual, free []int) {
+	dx := rng.Intn(len(ind.Timetables))
+	day := free[rng.Intn(len(free))]
+	if day < len(ind.Timetables[dx]) && len(ind.Timetables[dx][day]) > 1 {
+		d := ind.Timetables[dx][day]
+		slot1, slot2 := rng.Intn(len(d)), rng.Intn(len(d))
+		first, second := d.Slot(slot1), d.Slot(slot2)
+		ind.Timetables[dx][day] = d.Place(slot1, second).Place(slot2, first)
+	}
 }
 
-func (s *Solver) crossover(p1, p2 Individual) Individual {
-	child := Individual{
-		Timetables: make([]output.Days, len(p1.Timetables)),
+// moveLesson moves a random lesson of a random division from one free day to the end
+// of another, the hours of a multi-hour block are moved together.
+func moveLesson(rng *rand.Rand, ind *Individual, free []int) {
+	if len(free) < 2 {
+		return
+	}
+	dx := rng.Intn(len(ind.Timetables))
+	fi := rng.Intn(len(free))
+	ti := rng.Intn(len(free) - 1)
+	if ti >= fi {
+		ti++
+	}
+	from, to := free[fi], free[ti]
+	if from >= len(ind.Timetables[dx]) || to >= len(ind.Timetables[dx]) {
+		return
+	}
+
+	d := ind.Timetables[dx][from]
+	if len(d) == 0 {
+		return
+	}
+	slot := rng.Intn(len(d))
+	if d.IsFree(slot) {
+		return
+	}
+
+	// Lessons are placed in the first subject of a group, so that's where the block is
+	block := d.Slot(slot)[0].Block
+	kept := make(output.Day, 0, len(d))
+	target := ind.Timetables[dx][to]
+	for i, sg := range d {
+		if i == slot || (block > 0 && len(sg) > 0 && sg[0].Block == block) {
+			target = target.Place(len(target), sg)
+		} else {
+			kept = append(kept, sg)
+		}
 	}
-	copy(child.Timetables, p1.Timetables)
-	if len(p1.Timetables) > 0 {
-		dx := rand.Intn(len(p1.Timetables))
-		for i := 0; i < 2; i++ {
-			day := rand.Intn(5)
-			child.Timetables[dx][day] = p2.Timetables[dx][day]
+	ind.Timetables[dx][from] = kept
+	ind.Timetables[dx][to] = target
+}
+
+// repair removes the empty subjects groups between and after the lessons of every free
+// day, sliding the later lessons forward, so the days of a child never have gaps, empty
+// groups before the first lesson are kept since they pad the division's earliest start,
+// days booked solid through the lunch window get their lunch break back.
+func (s *Solver) repair(ind *Individual) {
+	for dx := range ind.Timetables {
+		for _, day := range s.freeDays() {
+			if day >= len(ind.Timetables[dx]) {
+				continue
+			}
+			d := ind.Timetables[dx][day]
+			compacted := d[:0]
+			started := false
+			for _, sg := range d {
+				empty := sg.IsEmpty()
+				if !empty {
+					started = true
+				}
+				if !empty || !started {
+					compacted = append(compacted, sg)
+				}
+			}
+			// A day without lessons keeps no padding either
+			if !started {
+				compacted = compacted[:0]
+			}
+			ind.Timetables[dx][day] = s.insertLunchBreak(compacted)
 		}
 	}
-	return child
 }
 
-func (s *Solver) mutate(ind *Individual) {
-	if rand.Float64() > s.MutationRate {
+// repairTeacherOverlap looks for a teacher that is shared by several divisions and
+// teaches two of them at the same time, and moves the lesson of one of the divisions
+// to a random other slot of the same day, with the same probability as mutation.
+func (s *Solver) repairTeacherOverlap(rng *rand.Rand, ind *Individual, rate float64) {
+	if rng.Float64() > rate {
 		return
 	}
-	// Randomly pick a division/day and swap two slots if possible
-	dx := rand.Intn(len(ind.Timetables))
-	day := rand.Intn(5)
-	if len(ind.Timetables[dx][day]) > 1 {
-		slot1 := rand.Intn(len(ind.Timetables[dx][day]))
-		slot2 := rand.Intn(len(ind.Timetables[dx][day]))
-		ind.Timetables[dx][day][slot1], ind.Timetables[dx][day][slot2] = ind.Timetables[dx][day][slot2], ind.Timetables[dx][day][slot1]
+
+	for _, day := range s.freeDays() {
+		type slotKey struct {
+			slot    int
+			teacher input.Teacher
+		}
+		used := make(map[slotKey]bool)
+		for dx := range ind.Timetables {
+			for slot, sg := range ind.Timetables[dx][day] {
+				for _, subj := range sg {
+					if subj.GlobalSubject == nil || subj.Teacher == nil {
+						continue
+					}
+					key := slotKey{slot: slot, teacher: *subj.Teacher}
+					if !used[key] {
+						used[key] = true
+						continue
+					}
+
+					d := ind.Timetables[dx][day]
+					if len(d) < 2 {
+						continue
+					}
+					other := rng.Intn(len(d) - 1)
+					if other >= slot {
+						other++
+					}
+					d[slot], d[other] = d[other], d[slot]
+					return
+				}
+			}
+		}
 	}
 }
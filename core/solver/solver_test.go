@@ -0,0 +1,1114 @@
+// core/solver/solver_test.go
+package solver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+// overbookedInput returns input data in which a teacher teaches both divisions every slot
+// of the week, so every timetable double books them.
+func overbookedInput() input.InputData {
+	math := input.GlobalSubject("matematyka")
+	lj := input.Teacher("LJ")
+	subj := input.Subject{GlobalSubject: &math, Teacher: &lj, Allocation: input.Allocation{8, 8, 8, 8, 8}}
+	return input.InputData{
+		GlobalSubjects: []input.GlobalSubject{math},
+		Teachers:       []input.Teacher{lj},
+		Divisions: []input.Division{
+			{Name: "1A", Subjects: []input.Subject{subj}},
+			{Name: "1B", Subjects: []input.Subject{subj}},
+		},
+	}
+}
+
+func TestSeedPerGeneration(t *testing.T) {
+	s := &Solver{Seed: 3, SeedPerGeneration: true}
+
+	// A generation draws the same numbers however many generations ran before it
+	draws := func(generation int) []int {
+		rng := s.generationRand(s.Seed, generation)
+		var n []int
+		for range 10 {
+			n = append(n, rng.Intn(1000))
+		}
+		return n
+	}
+	if a, b := draws(500), draws(500); !reflect.DeepEqual(a, b) {
+		t.Errorf("generation 500 drew %v and then %v", a, b)
+	}
+	if a, b := draws(500), draws(499); reflect.DeepEqual(a, b) {
+		t.Errorf("generations 499 and 500 both drew %v", a)
+	}
+
+	in := input.ExampleInputData
+	solver := Solver{PopulationSize: 20, Generations: 30, MutationRate: 0.2, Seed: 3, SeedPerGeneration: true}
+	if a, b := solver.Solve(in), solver.Solve(in); !reflect.DeepEqual(a, b) {
+		t.Errorf("seeded solves diverged, fitness %d and %d", a.Fitness, b.Fitness)
+	}
+}
+
+func TestTeacherConflicts(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	be, gr := input.Teacher("Be"), input.Teacher("gr")
+	in := input.InputData{TeacherConflicts: [][2]input.Teacher{{be, gr}}}
+	s := &Solver{}
+
+	concurrent := Individual{Timetables: []output.Days{
+		{{taught(&a, &be)}},
+		{{taught(&b, &gr)}},
+	}}
+	staggered := Individual{Timetables: []output.Days{
+		{{taught(&a, &be)}},
+		{{{}, taught(&b, &gr)}},
+	}}
+	if n := s.hardViolations(concurrent, in).TeacherConflicts; n != 1 {
+		t.Errorf("concurrent conflicts = %d, want 1", n)
+	}
+	if n := s.hardViolations(staggered, in).TeacherConflicts; n != 0 {
+		t.Errorf("staggered conflicts = %d, want 0", n)
+	}
+	if s.fitness(concurrent, in) <= s.fitness(staggered, in) {
+		t.Error("teaching at the same time doesn't score worse than staggered")
+	}
+}
+
+func TestRepairTeacherOverlap(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	lj, gr := input.Teacher("LJ"), input.Teacher("gr")
+	// LJ teaches both divisions in the first slot of Monday
+	ind := Individual{Timetables: []output.Days{
+		{{taught(&a, &lj), taught(&b, &gr)}, nil, nil, nil, nil},
+		{{taught(&a, &lj), taught(&b, &gr)}, nil, nil, nil, nil},
+	}}
+	s := &Solver{MutationRate: 1}
+	before := s.hardViolations(ind, input.InputData{}).TeacherOverlaps
+	s.repairTeacherOverlap(rand.New(rand.NewSource(1)), &ind, 1)
+	if after := s.hardViolations(ind, input.InputData{}).TeacherOverlaps; before == 0 || after >= before {
+		t.Errorf("teacher overlaps = %d after the repair, want fewer than %d", after, before)
+	}
+}
+
+func TestBestOf(t *testing.T) {
+	fits := []fitInd{
+		{fitness: 5, scores: []int{5}},
+		{fitness: 3, scores: []int{3}},
+		{fitness: 3, scores: []int{3}},
+		{fitness: 4, scores: []int{4}},
+	}
+	fits[1].ind.Timetables = []output.Days{{}}
+	if best := bestOf(fits); best.fitness != 3 || len(best.ind.Timetables) != 1 {
+		t.Errorf("bestOf = %+v, want the first individual with fitness 3", best)
+	}
+}
+
+// The best individual is picked after the workers are done, run it with -race
+func TestSolveDeterministicWithWorkers(t *testing.T) {
+	s := Solver{PopulationSize: 30, Generations: 20, MutationRate: 0.2, Seed: 5, MaxParallelism: 8}
+	want, _ := json.Marshal(s.Solve(input.ExampleInputData))
+	for range 3 {
+		if got, _ := json.Marshal(s.Solve(input.ExampleInputData)); !bytes.Equal(got, want) {
+			t.Fatal("solves with the same seed picked different best individuals")
+		}
+	}
+}
+
+func TestForbiddenDays(t *testing.T) {
+	pe := input.GlobalSubject("wf")
+	in := input.InputData{
+		GlobalSubjects: []input.GlobalSubject{pe},
+		Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+			{GlobalSubject: &pe, Allocation: input.Allocation{1, 1, 1}, ForbiddenDays: []int{0, 4}},
+		}}},
+	}
+	s := &Solver{}
+
+	onMonday := week(lessons(&pe), lessons(&pe), lessons(&pe), nil, nil)
+	midweek := week(nil, lessons(&pe), lessons(&pe), lessons(&pe), nil)
+	if n := s.hardViolations(onMonday, in).ForbiddenDays; n != 1 {
+		t.Errorf("forbidden days on Monday = %d, want 1", n)
+	}
+	if n := s.hardViolations(midweek, in).ForbiddenDays; n != 0 {
+		t.Errorf("forbidden days midweek = %d, want 0", n)
+	}
+
+	// New individuals never place it on Monday or Friday
+	rng := rand.New(rand.NewSource(1))
+	for range 20 {
+		ind := s.randomIndividual(rng, in)
+		for _, day := range []int{0, 4} {
+			for _, sg := range ind.Timetables[0][day] {
+				for _, subj := range sg {
+					if subj.GlobalSubject != nil {
+						t.Fatalf("subject placed on forbidden day %d: %v", day, ind.Timetables[0])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestObjectivesOrder(t *testing.T) {
+	x, y := input.GlobalSubject("x"), input.GlobalSubject("y")
+	placed := func(ind Individual) int {
+		n := 0
+		for _, sg := range ind.Timetables[0][0] {
+			if sg[0].GlobalSubject != nil {
+				n++
+			}
+		}
+		return n
+	}
+	// The first objective wants a single lesson, the second one wants two lessons or a lesson of y
+	first := func(ind Individual, in input.InputData) int { return placed(ind) - 1 }
+	second := func(ind Individual, in input.InputData) int {
+		if placed(ind) == 2 || *ind.Timetables[0][0][0][0].GlobalSubject == y {
+			return 0
+		}
+		return 5
+	}
+	s := &Solver{Objectives: []Objective{first, second}}
+
+	short, long, other := week(lessons(&x)), week(lessons(&x, &x)), week(lessons(&y))
+	fits := []fitInd{s.score(long, input.InputData{}), s.score(short, input.InputData{}), s.score(other, input.InputData{})}
+	slices.SortFunc(fits, func(a, b fitInd) int { return slices.Compare(a.scores, b.scores) })
+
+	// The second objective only decides between individuals equal in the first one
+	var order []int
+	for _, f := range fits {
+		order = append(order, first(f.ind, input.InputData{}), second(f.ind, input.InputData{}))
+	}
+	if want := []int{0, 0, 0, 5, 1, 0}; !slices.Equal(order, want) {
+		t.Errorf("scores in order = %v, want %v", order, want)
+	}
+	if best := bestOf(fits); *best.ind.Timetables[0][0][0][0].GlobalSubject != y {
+		t.Errorf("best individual = %v, want the lesson of y", best.ind.Timetables[0][0])
+	}
+}
+
+func TestEarliestStart(t *testing.T) {
+	in := input.ExampleInputData
+	in.Divisions = slices.Clone(in.Divisions)
+	in.Divisions[0].EarliestStart = 1
+	s := &Solver{}
+
+	// New individuals keep the first slot free
+	rng := rand.New(rand.NewSource(1))
+	for range 20 {
+		ind := s.randomIndividual(rng, in)
+		for day, d := range ind.Timetables[0] {
+			if len(d) == 0 {
+				continue
+			}
+			for _, subj := range d[0] {
+				if subj.GlobalSubject != nil {
+					t.Fatalf("%s: slot 1 = %v, want it free", output.DayName(day), d[0])
+				}
+			}
+		}
+		if n := s.hardViolations(ind, in).EarlyLessons; n != 0 {
+			t.Fatalf("%d early lessons in a new individual, want 0", n)
+		}
+	}
+
+	a := input.GlobalSubject("a")
+	one := input.InputData{Divisions: []input.Division{{Name: "A", EarliestStart: 1}}}
+	early := week(lessons(&a, &a), lessons(nil, &a))
+	if n := s.hardViolations(early, one).EarlyLessons; n != 1 {
+		t.Errorf("%d early lessons, want 1", n)
+	}
+}
+
+func TestFixedDays(t *testing.T) {
+	in := input.ExampleInputData
+	published := (&Solver{PopulationSize: 20, Generations: 10, MutationRate: 0.2, Seed: 1}).Solve(in)
+
+	s := &Solver{PopulationSize: 20, Generations: 30, MutationRate: 0.5, Seed: 2,
+		FixedDays: []int{0, 2}, FixedTimetables: published.DivisionsTimetables}
+	out := s.Solve(in)
+	for dIdx := range in.Divisions {
+		for _, day := range s.FixedDays {
+			want, _ := json.Marshal(published.DivisionsTimetables[dIdx][day])
+			got, _ := json.Marshal(out.DivisionsTimetables[dIdx][day])
+			if !bytes.Equal(got, want) {
+				t.Errorf("division %d, %s changed:\n%s\nwant:\n%s", dIdx, output.DayName(day), got, want)
+			}
+		}
+	}
+}
+
+func TestDeterministicClassrooms(t *testing.T) {
+	s := &Solver{DeterministicClassrooms: true}
+	for _, div := range input.ExampleInputData.Divisions {
+		for _, subj := range div.Subjects {
+			want := s.pickClassroom(rand.New(rand.NewSource(1)), subj)
+			for seed := int64(2); seed < 10; seed++ {
+				if got := s.pickClassroom(rand.New(rand.NewSource(seed)), subj); got != want {
+					t.Fatalf("%s: classroom %v with seed %d, want %v", *subj.GlobalSubject, got, seed, want)
+				}
+			}
+			if want != nil && want != subj.Classrooms[0] {
+				t.Errorf("%s: classroom %v, want the first one %v", *subj.GlobalSubject, *want, *subj.Classrooms[0])
+			}
+		}
+	}
+}
+
+func TestWholeDivision(t *testing.T) {
+	english, assembly := input.GlobalSubject("angielski"), input.GlobalSubject("godz.wych")
+	ak, lj := input.Teacher("AK"), input.Teacher("LJ")
+	one, two := input.SubjectsGroupOne, input.SubjectsGroupTwo
+	div := input.Division{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &english, Teacher: &ak, Group: one, Allocation: input.Allocation{1, 1, 1}},
+		{GlobalSubject: &english, Teacher: &lj, Group: two, Allocation: input.Allocation{1, 1, 1}},
+		{GlobalSubject: &assembly, Teacher: &lj, WholeDivision: true, Allocation: input.Allocation{1, 1}},
+	}}
+	in := input.InputData{GlobalSubjects: []input.GlobalSubject{english, assembly}, Teachers: []input.Teacher{ak, lj}, Divisions: []input.Division{div}}
+
+	shared := output.SubjectsGroup{{GlobalSubject: &assembly, Teacher: &lj}, {GlobalSubject: &english, Teacher: &ak, Group: &one}}
+	if !sharesWholeDivisionSlot(div, shared) {
+		t.Error("a whole division subject next to a group isn't reported")
+	}
+
+	out := (&Solver{PopulationSize: 20, Generations: 30, MutationRate: 0.3, Seed: 1}).Solve(in)
+	for day, d := range out.DivisionsTimetables[0] {
+		for slot, sg := range d {
+			if sharesWholeDivisionSlot(div, sg) {
+				t.Errorf("%s slot %d: whole division subject shares the slot: %v", output.DayName(day), slot+1, sg)
+			}
+		}
+	}
+}
+
+func TestSolveAll(t *testing.T) {
+	s := &Solver{PopulationSize: 25, Generations: 10, MutationRate: 0.2, Seed: 1}
+	ranked := s.SolveAll(input.ExampleInputData)
+	if len(ranked) != s.PopulationSize {
+		t.Fatalf("%d individuals, want %d", len(ranked), s.PopulationSize)
+	}
+	if !slices.IsSortedFunc(ranked, func(a, b RankedIndividual) int { return a.Fitness - b.Fitness }) {
+		t.Error("individuals aren't sorted by fitness")
+	}
+	for _, r := range ranked {
+		if f := s.fitness(r.Individual, input.ExampleInputData); f != r.Fitness {
+			t.Fatalf("ranked fitness %d, want %d", r.Fitness, f)
+		}
+	}
+}
+
+func TestDivisionGaps(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &a, Allocation: input.Allocation{1}},
+		{GlobalSubject: &b, Allocation: input.Allocation{1}},
+	}}}}
+	s := &Solver{}
+
+	holed, compacted := lessons(&a, nil, &b), lessons(&a, &b)
+	if gaps := s.divisionGaps(holed); gaps != 1 {
+		t.Errorf("gaps of a day with a hole = %d, want 1", gaps)
+	}
+	if gaps := s.divisionGaps(compacted); gaps != 0 {
+		t.Errorf("gaps of a compacted day = %d, want 0", gaps)
+	}
+	h, c := s.Evaluate(week(holed, nil, nil, nil, nil), in), s.Evaluate(week(compacted, nil, nil, nil, nil), in)
+	if h.Total() <= c.Total() {
+		t.Errorf("day with a hole scores %d, want more than the compacted %d", h.Total(), c.Total())
+	}
+}
+
+func TestSolveContextTimeout(t *testing.T) {
+	s := &Solver{PopulationSize: 20, Generations: 1_000_000, MutationRate: 0.1, Seed: 1}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	res := s.SolveContext(ctx, input.ExampleInputData)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("solve took %v after a 50ms timeout", elapsed)
+	}
+	if res.Stop != StopCancelled || res.Generations >= s.Generations {
+		t.Errorf("solve stopped by %s after %d generations, want cancelled early", res.Stop, res.Generations)
+	}
+	if len(res.Output.DivisionsTimetables) != len(input.ExampleInputData.Divisions) {
+		t.Errorf("partial result has %d timetables, want one per division", len(res.Output.DivisionsTimetables))
+	}
+}
+
+func TestBestNeverRegresses(t *testing.T) {
+	var history []int
+	s := &Solver{PopulationSize: 20, Generations: 40, MutationRate: 0.5, Seed: 7,
+		OnGeneration: func(_ int, best int) { history = append(history, best) }}
+	res := s.SolveContext(context.Background(), input.ExampleInputData)
+
+	if len(history) != res.Generations {
+		t.Fatalf("OnGeneration was called %d times in %d generations", len(history), res.Generations)
+	}
+	for gen := 1; gen < len(history); gen++ {
+		if history[gen] > history[gen-1] {
+			t.Errorf("best fitness rose from %d to %d in generation %d", history[gen-1], history[gen], gen)
+		}
+	}
+
+	// The recorded best isn't scrambled by the generations after it was found
+	best := Individual{Timetables: res.Output.DivisionsTimetables}
+	if got := s.fitness(best, input.ExampleInputData); got != res.Output.Fitness {
+		t.Errorf("best individual scores %d, but was recorded with %d", got, res.Output.Fitness)
+	}
+}
+
+func TestSerialParallelEquivalence(t *testing.T) {
+	serial := Solver{PopulationSize: 30, Generations: 20, MutationRate: 0.2, Seed: 9, MaxParallelism: 1}
+	parallel := serial
+	parallel.MaxParallelism = 8
+
+	want, _ := json.Marshal(serial.Solve(input.ExampleInputData))
+	if got, _ := json.Marshal(parallel.Solve(input.ExampleInputData)); !bytes.Equal(got, want) {
+		t.Error("serial and parallel solves with the same seed differ")
+	}
+}
+
+func BenchmarkScoreAll(b *testing.B) {
+	in := input.ExampleInputData
+	pop := (&Solver{PopulationSize: 200}).initializePopulation(rand.New(rand.NewSource(1)), in)
+	indices := make([]int, len(pop))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	for _, bm := range []struct {
+		name        string
+		parallelism int
+	}{{"serial", 1}, {"parallel", 0}} {
+		b.Run(bm.name, func(b *testing.B) {
+			s := &Solver{MaxParallelism: bm.parallelism}
+			fits := make([]fitInd, len(pop))
+			for range b.N {
+				s.scoreAll(pop, in, fits, indices)
+			}
+		})
+	}
+}
+
+func TestSeedReproducible(t *testing.T) {
+	s := Solver{PopulationSize: 20, Generations: 15, MutationRate: 0.2}
+	first := s.SolveContext(context.Background(), input.ExampleInputData)
+	if first.Seed == 0 {
+		t.Fatal("a time based seed isn't reported")
+	}
+
+	s.Seed = first.Seed
+	again := s.SolveContext(context.Background(), input.ExampleInputData)
+	if again.Seed != first.Seed {
+		t.Errorf("seed = %d, want the configured %d", again.Seed, first.Seed)
+	}
+	want, _ := json.Marshal(first.Output)
+	if got, _ := json.Marshal(again.Output); !bytes.Equal(got, want) {
+		t.Error("solving again with the reported seed gave different timetables")
+	}
+}
+
+func TestStagnationLimit(t *testing.T) {
+	s := &Solver{PopulationSize: 10, Generations: 1000, MutationRate: 0.1, Seed: 1, StagnationLimit: 5}
+	res := s.SolveContext(context.Background(), overbookedInput())
+	if res.Stop != StopStagnated {
+		t.Fatalf("solve stopped by %s, want stagnated", res.Stop)
+	}
+	if res.Generations >= s.Generations || res.Generations < s.StagnationLimit {
+		t.Errorf("solve stopped after %d generations, want at least %d and fewer than %d", res.Generations, s.StagnationLimit, s.Generations)
+	}
+	if res.Output.Fitness == 0 {
+		t.Error("unsatisfiable input reached fitness 0")
+	}
+}
+
+func TestSplitBlocks(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &a, Allocation: input.Allocation{2}},
+		{GlobalSubject: &b, Allocation: input.Allocation{1}},
+	}}}}
+	block := output.SubjectsGroup{{GlobalSubject: &a, Block: 1}}
+	single := output.SubjectsGroup{{GlobalSubject: &b}}
+	s := &Solver{}
+
+	split, together := output.Day{block, single, block}, output.Day{block, block, single}
+	if n := splitBlocks(split); n != 1 {
+		t.Errorf("split blocks of slots 0 and 2 = %d, want 1", n)
+	}
+	if n := splitBlocks(together); n != 0 {
+		t.Errorf("split blocks of slots 0 and 1 = %d, want 0", n)
+	}
+	sp, tg := s.Evaluate(week(split, nil, nil, nil, nil), in), s.Evaluate(week(together, nil, nil, nil, nil), in)
+	if sp.Total() <= tg.Total() {
+		t.Errorf("split block scores %d, want more than the consecutive %d", sp.Total(), tg.Total())
+	}
+}
+
+func TestDivisionWeight(t *testing.T) {
+	a := input.GlobalSubject("a")
+	div := func(name string, weight uint) input.Division {
+		return input.Division{Name: name, Weight: weight, Subjects: []input.Subject{
+			{GlobalSubject: &a, Group: input.SubjectsGroupNone, Allocation: input.Allocation{1}},
+		}}
+	}
+	in := input.InputData{Divisions: []input.Division{div("light", 1), div("heavy", 5)}}
+	none := input.SubjectsGroupNone
+	complete := output.Days{{{{GlobalSubject: &a, Group: &none}}}, nil, nil, nil, nil}
+	missing := output.Days{nil, nil, nil, nil, nil}
+	s := &Solver{}
+
+	lightMissing := s.Evaluate(Individual{Timetables: []output.Days{missing, complete}}, in)
+	heavyMissing := s.Evaluate(Individual{Timetables: []output.Days{complete, missing}}, in)
+	if heavyMissing.UnmetAllocation != 5*lightMissing.UnmetAllocation || lightMissing.UnmetAllocation == 0 {
+		t.Errorf("unmet allocation = %d for the heavy division, want 5 times the light one's %d",
+			heavyMissing.UnmetAllocation, lightMissing.UnmetAllocation)
+	}
+	if heavyMissing.Total() <= lightMissing.Total() {
+		t.Errorf("heavy division's violation scores %d, want more than the light one's %d", heavyMissing.Total(), lightMissing.Total())
+	}
+}
+
+func TestUnavailableSlots(t *testing.T) {
+	a := input.GlobalSubject("a")
+	lj := input.Teacher("LJ")
+	in := input.InputData{
+		Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+			{GlobalSubject: &a, Teacher: &lj, Group: input.SubjectsGroupNone, Allocation: input.Allocation{1}},
+		}}},
+		TeacherConstraints: map[input.Teacher]input.TeacherConstraints{lj: {Unavailable: []input.BlockedSlot{{Day: 0, Slot: 0}}}},
+	}
+	none := input.SubjectsGroupNone
+	at := func(day, slot int) Individual {
+		days := make(output.Days, 5)
+		days[day] = make(output.Day, slot+1)
+		days[day][slot] = output.SubjectsGroup{{GlobalSubject: &a, Teacher: &lj, Group: &none}}
+		return Individual{Timetables: []output.Days{days}}
+	}
+	s := &Solver{}
+
+	blocked := s.Evaluate(at(0, 0), in)
+	if blocked.UnavailableSlots == 0 {
+		t.Fatal("lesson in the teacher's blocked slot isn't penalized")
+	}
+	for day := range 5 {
+		for slot := range 4 {
+			if day == 0 && slot == 0 {
+				continue
+			}
+			if alt := s.Evaluate(at(day, slot), in); alt.Total() >= blocked.Total() {
+				t.Errorf("day %d slot %d scores %d, want less than the blocked slot's %d", day, slot, alt.Total(), blocked.Total())
+			}
+		}
+	}
+}
+
+func TestMoveMutation(t *testing.T) {
+	a, b, c := input.GlobalSubject("a"), input.GlobalSubject("b"), input.GlobalSubject("c")
+	in := input.InputData{Divisions: []input.Division{{Name: "A", Subjects: []input.Subject{
+		{GlobalSubject: &a, Allocation: input.Allocation{1}},
+		{GlobalSubject: &b, Allocation: input.Allocation{1}},
+		{GlobalSubject: &c, Allocation: input.Allocation{1}},
+	}}}}
+	// Every lesson starts on Monday, reports whether a lesson of a left it within the mutations
+	movesA := func(s *Solver) bool {
+		rng := rand.New(rand.NewSource(1))
+		ind := week(lessons(&a, &b, &c), nil, nil, nil, nil)
+		for range 200 {
+			s.mutate(rng, &ind, in, 1)
+			for day := 1; day < len(ind.Timetables[0]); day++ {
+				if hasSubject(ind.Timetables[0][day], in.Divisions[0].Subjects[0]) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if movesA(&Solver{}) {
+		t.Error("swaps alone moved a lesson to another day")
+	}
+	if !movesA(&Solver{MoveMutationRate: 0.5}) {
+		t.Error("a lesson never moved to another day")
+	}
+}
+
+func TestRepair(t *testing.T) {
+	a, b, c := input.GlobalSubject("a"), input.GlobalSubject("b"), input.GlobalSubject("c")
+	s := &Solver{}
+
+	// Monday starts late and has holes between and after its lessons, Tuesday has nothing but holes
+	ind := week(lessons(nil, &a, nil, nil, &b, nil, &c, nil), lessons(nil, nil), nil, nil, nil)
+	s.repair(&ind)
+	want := [][]*input.GlobalSubject{{nil, &a, &b, &c}, {}, {}, {}, {}}
+	for day, subjects := range want {
+		d := ind.Timetables[0][day]
+		if len(d) != len(subjects) {
+			t.Errorf("day %d has %d slots after repair, want %d", day, len(d), len(subjects))
+			continue
+		}
+		for slot, subj := range subjects {
+			if got := d[slot]; (subj == nil) != got.IsEmpty() || (subj != nil && got[0].GlobalSubject != subj) {
+				t.Errorf("day %d slot %d = %v after repair, want %v", day, slot, got, subj)
+			}
+		}
+	}
+}
+
+func TestSolveTopK(t *testing.T) {
+	in := input.ExampleInputData
+	s := &Solver{PopulationSize: 30, Generations: 10, MutationRate: 0.3, Seed: 5}
+	top := s.SolveTopK(in, 5)
+	if len(top) == 0 || len(top) > 5 {
+		t.Fatalf("got %d timetables, want 1 to 5", len(top))
+	}
+
+	seen := make(map[string]bool)
+	for i, out := range top {
+		if i > 0 && out.Fitness < top[i-1].Fitness {
+			t.Errorf("timetable %d has fitness %d, better than the %d before it", i, out.Fitness, top[i-1].Fitness)
+		}
+		if len(out.DivisionsTimetables) != len(in.Divisions) {
+			t.Errorf("timetable %d has %d divisions, want %d", i, len(out.DivisionsTimetables), len(in.Divisions))
+		}
+		for dIdx, days := range out.DivisionsTimetables {
+			if len(days) != defaultDaysPerWeek {
+				t.Errorf("timetable %d division %d has %d days, want %d", i, dIdx, len(days), defaultDaysPerWeek)
+			}
+		}
+
+		data, _ := json.Marshal(out.DivisionsTimetables)
+		if seen[string(data)] {
+			t.Errorf("timetable %d is identical to an earlier one", i)
+		}
+		seen[string(data)] = true
+	}
+}
+
+func TestOnGeneration(t *testing.T) {
+	var gens, history []int
+	s := &Solver{PopulationSize: 20, Generations: 25, MutationRate: 0.3, Seed: 3,
+		OnGeneration: func(gen int, best int) {
+			gens = append(gens, gen)
+			history = append(history, best)
+		}}
+	res := s.SolveContext(context.Background(), input.ExampleInputData)
+
+	// Called once for each generation, in order
+	for i, gen := range gens {
+		if gen != i {
+			t.Fatalf("call %d was for generation %d, want %d", i, gen, i)
+		}
+	}
+	if len(gens) != res.Generations {
+		t.Fatalf("OnGeneration was called %d times in %d generations", len(gens), res.Generations)
+	}
+	for gen := 1; gen < len(history); gen++ {
+		if history[gen] > history[gen-1] {
+			t.Errorf("best fitness rose from %d to %d in generation %d", history[gen-1], history[gen], gen)
+		}
+	}
+	// The best fitness was already updated for the generation it's reported with
+	if last := history[len(history)-1]; last != res.Output.Fitness {
+		t.Errorf("last reported fitness = %d, want the result's %d", last, res.Output.Fitness)
+	}
+}
+
+func TestClone(t *testing.T) {
+	a, b := input.GlobalSubject("a"), input.GlobalSubject("b")
+	orig := week(lessons(&a, &a), lessons(&a))
+	clone := orig.Clone()
+
+	// Change the clone at every depth, a subject, a group, a day and a division
+	clone.Timetables[0][0][0][0].GlobalSubject = &b
+	clone.Timetables[0][0][1] = append(clone.Timetables[0][0][1], output.Subject{GlobalSubject: &b})
+	clone.Timetables[0][1] = append(clone.Timetables[0][1], output.SubjectsGroup{{GlobalSubject: &b}})
+	clone.Timetables = append(clone.Timetables, output.Days{lessons(&b)})
+
+	want := week(lessons(&a, &a), lessons(&a))
+	if len(orig.Timetables) != 1 {
+		t.Fatalf("original has %d divisions after changing the clone, want 1", len(orig.Timetables))
+	}
+	for day, d := range want.Timetables[0] {
+		got := orig.Timetables[0][day]
+		if len(got) != len(d) {
+			t.Errorf("original day %d has %d slots after changing the clone, want %d", day, len(got), len(d))
+			continue
+		}
+		for slot := range d {
+			if len(got[slot]) != 1 || got[slot][0].GlobalSubject != &a {
+				t.Errorf("original day %d slot %d = %v after changing the clone, want just a", day, slot, got[slot])
+			}
+		}
+	}
+}
+
+func TestFourGroups(t *testing.T) {
+	english := input.GlobalSubject("angielski")
+	groups := []input.SubjectsGroupType{input.SubjectsGroupOne, input.SubjectsGroupTwo, input.SubjectsGroupThree, input.SubjectsGroupFour}
+	teachers := []input.Teacher{"AK", "LJ", "MN", "PW"}
+	in := input.InputData{GlobalSubjects: []input.GlobalSubject{english}, Teachers: teachers, Divisions: []input.Division{{Name: "1A"}}}
+	var slot output.SubjectsGroup
+	for i := range groups {
+		in.Divisions[0].Subjects = append(in.Divisions[0].Subjects,
+			input.Subject{GlobalSubject: &english, Teacher: &teachers[i], Group: groups[i], Allocation: input.Allocation{1}})
+		slot = append(slot, output.Subject{GlobalSubject: &english, Teacher: &teachers[i], Group: &groups[i]})
+	}
+	if errs := in.Validate(); len(errs) > 0 {
+		t.Fatalf("input is invalid: %v", errs)
+	}
+
+	s := &Solver{PopulationSize: 10, Generations: 10, MutationRate: 0.2, Seed: 1}
+	out := s.Solve(in)
+	if errs := output.Verify(out); len(out.DivisionsTimetables) != 1 || len(errs) > 0 {
+		t.Fatalf("solved %d divisions with errors %v, want 1 valid division", len(out.DivisionsTimetables), errs)
+	}
+	taughtGroups := 0
+	for _, day := range out.DivisionsTimetables[0] {
+		for _, sg := range day {
+			taughtGroups += len(sg)
+		}
+	}
+	if taughtGroups != len(groups) {
+		t.Errorf("solved timetable teaches %d group lessons, want %d", taughtGroups, len(groups))
+	}
+
+	// All four groups taught in parallel are a valid slot, also after a JSON round trip
+	ind := week(output.Day{slot}, nil, nil, nil, nil)
+	if v := s.hardViolations(ind, in); !v.Feasible() {
+		t.Errorf("four parallel groups have violations %s", v)
+	}
+	data, err := json.Marshal(output.OutputData{DivisionsTimetables: ind.Timetables})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded output.OutputData
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	got := decoded.DivisionsTimetables[0][0][0]
+	if len(got) != len(groups) {
+		t.Fatalf("decoded slot has %d groups, want %d", len(got), len(groups))
+	}
+	for i, subj := range got {
+		if subj.Group == nil || *subj.Group != groups[i] || *subj.Teacher != teachers[i] {
+			t.Errorf("decoded group %d = %+v, want group %q taught by %s", i, subj, groups[i], teachers[i])
+		}
+	}
+}
+
+func TestIslands(t *testing.T) {
+	in := input.ExampleInputData
+	islands := &Solver{PopulationSize: 20, Generations: 60, MutationRate: 0.2, Seed: 5, Islands: 2, MigrationInterval: 10}
+	single := &Solver{PopulationSize: 40, Generations: 60, MutationRate: 0.2, Seed: 5}
+
+	res := islands.SolveContext(context.Background(), in)
+	if res.Migrations == 0 {
+		t.Error("no individuals migrated between the islands")
+	}
+	if want := single.SolveContext(context.Background(), in).Output.Fitness; res.Output.Fitness > want {
+		t.Errorf("2 islands of 20 reached fitness %d, worse than a single island of 40 with %d", res.Output.Fitness, want)
+	}
+}
+
+func TestRoomsNeeded(t *testing.T) {
+	chem, math := input.GlobalSubject("chemia"), input.GlobalSubject("matematyka")
+	lab1, lab2, r12 := &input.Classroom{Name: "lab1"}, &input.Classroom{Name: "lab2"}, &input.Classroom{Name: "12"}
+	in := input.InputData{Divisions: []input.Division{
+		{Name: "1A", Subjects: []input.Subject{{GlobalSubject: &chem, Classrooms: []*input.Classroom{lab1, lab2, r12}, RoomsNeeded: 2, Allocation: input.Allocation{1, 1}}}},
+		{Name: "1B", Subjects: []input.Subject{{GlobalSubject: &math, Classrooms: []*input.Classroom{lab2}, Allocation: input.Allocation{1}}}},
+	}}
+	s := &Solver{PopulationSize: 10}
+
+	// Every hour reserves two distinct rooms
+	for _, ind := range s.initializePopulation(rand.New(rand.NewSource(1)), in) {
+		for _, d := range ind.Timetables[0] {
+			for _, sg := range d {
+				if sg.IsEmpty() {
+					continue
+				}
+				rooms := sg[0].Classrooms()
+				if len(rooms) != 2 || rooms[0].Name == rooms[1].Name {
+					t.Fatalf("split lab reserved %v, want 2 distinct rooms", rooms)
+				}
+			}
+		}
+	}
+
+	// The second room clashes just like the first
+	lab := output.SubjectsGroup{{GlobalSubject: &chem, Classroom: lab1, ExtraClassrooms: []*input.Classroom{lab2}}}
+	other := output.SubjectsGroup{{GlobalSubject: &math, Classroom: lab2}}
+	ind := Individual{Timetables: []output.Days{{output.Day{lab}}, {output.Day{other}}}}
+	if v := s.hardViolations(ind, in); v.ClassroomOverlaps != 1 {
+		t.Errorf("classroom overlaps = %d with the extra room booked twice, want 1", v.ClassroomOverlaps)
+	}
+}
+
+func TestCustomFitness(t *testing.T) {
+	in := input.ExampleInputData
+	var calls atomic.Int64
+	s := &Solver{PopulationSize: 10, Generations: 100, MutationRate: 0.1, Seed: 2,
+		Fitness: func(Individual, input.InputData) int {
+			calls.Add(1)
+			return 0
+		}}
+	res := s.SolveContext(context.Background(), in)
+
+	if res.Stop != StopOptimal || res.Generations != 1 || res.Output.Fitness != 0 {
+		t.Errorf("stopped by %s after %d generations with fitness %d, want optimal after the first one", res.Stop, res.Generations, res.Output.Fitness)
+	}
+	if n := calls.Load(); n > int64(s.PopulationSize)+1 {
+		t.Errorf("custom fitness was called %d times, want at most one population", n)
+	}
+	// The first individual is already optimal, nothing better can replace it
+	want, _ := json.Marshal(s.newState(in).pop[0].Timetables)
+	if got, _ := json.Marshal(res.Output.DivisionsTimetables); !bytes.Equal(got, want) {
+		t.Error("the result isn't the first individual of the population")
+	}
+}
+
+func TestConcurrentSolves(t *testing.T) {
+	in := input.ExampleInputData
+	results := make([]output.OutputData, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// A sweep over mutation rates, every solve has its own solver
+			s := &Solver{PopulationSize: 10, Generations: 10, MutationRate: 0.05 * float64(i+1), Seed: int64(i + 1)}
+			results[i] = s.Solve(in)
+		}()
+	}
+	wg.Wait()
+
+	for i, out := range results {
+		if len(out.DivisionsTimetables) != len(in.Divisions) {
+			t.Errorf("solve %d has %d divisions, want %d", i, len(out.DivisionsTimetables), len(in.Divisions))
+		}
+		if errs := output.Verify(out); len(errs) > 0 {
+			t.Errorf("solve %d is invalid: %v", i, errs)
+		}
+	}
+
+	// Running alongside the others didn't change a solve
+	alone := (&Solver{PopulationSize: 10, Generations: 10, MutationRate: 0.05, Seed: 1}).Solve(in)
+	want, _ := json.Marshal(alone)
+	if got, _ := json.Marshal(results[0]); !bytes.Equal(got, want) {
+		t.Error("a concurrent solve differs from the same solve run alone")
+	}
+}
+
+func BenchmarkSolve(b *testing.B) {
+	s := &Solver{PopulationSize: 40, Generations: 50, MutationRate: 0.1, Seed: 11}
+	b.ReportAllocs()
+	for range b.N {
+		s.Solve(input.ExampleInputData)
+	}
+}
+
+func TestSolveQuality(t *testing.T) {
+	s := &Solver{PopulationSize: 40, Generations: 200, MutationRate: 0.1, Seed: 11}
+	// A regression guard, not the best fitness the example data allows
+	if out := s.Solve(input.ExampleInputData); out.Fitness > 320 {
+		t.Errorf("best fitness = %d, want at most 320", out.Fitness)
+	}
+}
+
+func TestSolveWithBudget(t *testing.T) {
+	in := input.ExampleInputData
+	// Generations would end the solve long before the budget if they weren't ignored
+	s := &Solver{PopulationSize: 20, Generations: 1, MutationRate: 0.2, Seed: 6}
+	budget := 200 * time.Millisecond
+
+	start := time.Now()
+	res := s.SolveWithBudget(in, budget)
+	elapsed := time.Since(start)
+
+	// The generation running when the budget runs out is finished, so allow a little over
+	if elapsed < budget || elapsed > budget+time.Second {
+		t.Errorf("solve took %v, want about the %v budget", elapsed, budget)
+	}
+	if res.Stop != StopBudget {
+		t.Errorf("stopped by %s, want the budget", res.Stop)
+	}
+	if res.Generations <= 1 {
+		t.Errorf("ran %d generations, want more than Generations", res.Generations)
+	}
+	if errs := output.Verify(res.Output); len(res.Output.DivisionsTimetables) != len(in.Divisions) || len(errs) > 0 {
+		t.Errorf("solved %d divisions with errors %v, want %d valid ones", len(res.Output.DivisionsTimetables), errs, len(in.Divisions))
+	}
+}
+
+// captureStderr returns what f writes to os.Stderr.
+func captureStderr(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	f()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestWarnInfeasible(t *testing.T) {
+	in := overbookedInput()
+	s := &Solver{PopulationSize: 10, Generations: 5, MutationRate: 0.1, Seed: 1}
+	out := s.Solve(in)
+
+	var warned bool
+	stderr := captureStderr(t, func() {
+		warned = s.WarnInfeasible(os.Stderr, out, in)
+	})
+	if !warned {
+		t.Error("WarnInfeasible = false for an infeasible timetable")
+	}
+	if v := s.Check(out, in); v.TeacherOverlaps == 0 || !strings.Contains(stderr, "unresolved conflicts") || !strings.Contains(stderr, v.String()) {
+		t.Errorf("stderr = %q, want the unresolved teacher overlaps", stderr)
+	}
+
+	// A feasible timetable that isn't optimal still fails the run
+	var buf bytes.Buffer
+	if !s.WarnInfeasible(&buf, output.OutputData{Fitness: 5}, input.InputData{}) {
+		t.Error("WarnInfeasible = false for a nonzero fitness")
+	}
+	if s.WarnInfeasible(&buf, output.OutputData{}, input.InputData{}) {
+		t.Error("WarnInfeasible = true for an optimal timetable")
+	}
+}
+
+func TestMaxParallelism(t *testing.T) {
+	const limit = 2
+	for _, islands := range []int{1, 2, 8} {
+		var active, maxActive, maxGoroutines atomic.Int64
+		baseline := int64(runtime.NumGoroutine())
+
+		s := Solver{PopulationSize: 20, Generations: 20, MutationRate: 0.1, Seed: 1, MaxParallelism: limit, Islands: islands, MigrationInterval: 5}
+		s.Fitness = func(ind Individual, in input.InputData) int {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for m := maxActive.Load(); n > m && !maxActive.CompareAndSwap(m, n); m = maxActive.Load() {
+			}
+			// The goroutines started by the solve, the one running the test scores at most in their place
+			g := int64(runtime.NumGoroutine()) - baseline
+			// A goroutine that gave back its slot is counted until it returns, so let it return
+			for i := 0; g > limit && i < 100; i++ {
+				runtime.Gosched()
+				g = int64(runtime.NumGoroutine()) - baseline
+			}
+			for m := maxGoroutines.Load(); g > m && !maxGoroutines.CompareAndSwap(m, g); m = maxGoroutines.Load() {
+			}
+			return s.DefaultFitness(ind, in)
+		}
+		s.Solve(input.ExampleInputData)
+
+		if n := maxActive.Load(); n > limit {
+			t.Errorf("%d islands: %d fitness evaluations ran at once, want at most %d", islands, n, limit)
+		}
+		if n := maxGoroutines.Load(); n > limit {
+			t.Errorf("%d islands: %d goroutines were started at once, want at most %d", islands, n, limit)
+		}
+	}
+}
+
+func TestCapacityOverflows(t *testing.T) {
+	pe, eng := input.GlobalSubject("wf"), input.GlobalSubject("angielski")
+	one := input.SubjectsGroupOne
+	div := input.Division{Students: 30, Subjects: []input.Subject{
+		{GlobalSubject: &pe, Allocation: input.Allocation{1}},
+		{GlobalSubject: &eng, Group: one, Students: 15, Allocation: input.Allocation{1}},
+	}}
+	small := input.Classroom{Name: "12"}
+	capacities := map[string]uint{"12": 20}
+
+	tests := []struct {
+		name string
+		subj output.Subject
+		want int
+	}{
+		{"whole division", output.Subject{GlobalSubject: &pe, Classroom: &small}, 1},
+		{"group", output.Subject{GlobalSubject: &eng, Group: &one, Classroom: &small}, 0},
+		{"unlisted room", output.Subject{GlobalSubject: &pe, Classroom: &input.Classroom{Name: "sala", Capacity: 40}}, 0},
+		{"unlimited room", output.Subject{GlobalSubject: &pe, Classroom: &input.Classroom{Name: "aula"}}, 0},
+	}
+	for _, tt := range tests {
+		days := output.Days{{output.SubjectsGroup{tt.subj}}}
+		if got := capacityOverflows(days, div, capacities); got != tt.want {
+			t.Errorf("%s: overflows = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSixDayWeek(t *testing.T) {
+	s := &Solver{PopulationSize: 20, Generations: 20, MutationRate: 0.2, Seed: 1, DaysPerWeek: 6, MoveMutationRate: 0.3}
+	in := input.ExampleInputData
+	out := s.Solve(in)
+
+	saturday := 0
+	for dIdx, days := range out.DivisionsTimetables {
+		if len(days) != 6 {
+			t.Fatalf("division %d has %d days, want 6", dIdx, len(days))
+		}
+		saturday += lessonCount(days[5])
+	}
+	if saturday == 0 {
+		t.Error("no lessons are placed on Saturday")
+	}
+	if v := s.Check(out, in); v.UnmetHours != 0 {
+		t.Errorf("%d allocated hours aren't placed in the six day week", v.UnmetHours)
+	}
+	if got := s.fitness(Individual{Timetables: out.DivisionsTimetables}, in); got != out.Fitness {
+		t.Errorf("six day timetables score %d, but were reported with %d", got, out.Fitness)
+	}
+}
+
+func TestMutationKeepsSlots(t *testing.T) {
+	a, b, c, d := input.GlobalSubject("a"), input.GlobalSubject("b"), input.GlobalSubject("c"), input.GlobalSubject("d")
+	daily := input.Allocation{1, 1, 1, 1, 1}
+	in := input.InputData{Divisions: []input.Division{{Name: "A", EarliestStart: 1, Subjects: []input.Subject{
+		{GlobalSubject: &a, Allocation: daily}, {GlobalSubject: &b, Allocation: daily},
+		{GlobalSubject: &c, Allocation: daily}, {GlobalSubject: &d, Allocation: daily},
+	}}}}
+	// subjects returns the subject taught in every slot of every day, "" for a free slot
+	subjects := func(ind Individual) [][]string {
+		days := make([][]string, len(ind.Timetables[0]))
+		for day, dd := range ind.Timetables[0] {
+			for slot := range dd {
+				name := ""
+				if !dd.IsFree(slot) {
+					name = string(*dd.Slot(slot)[0].GlobalSubject)
+				}
+				days[day] = append(days[day], name)
+			}
+		}
+		return days
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	// A swap exchanges two slots of a day, free ones included, every other slot stays as it was
+	s := &Solver{}
+	ind := s.randomIndividual(rng, in)
+	for range 100 {
+		before := subjects(ind)
+		s.mutate(rng, &ind, in, 1)
+		after := subjects(ind)
+		for day := range before {
+			if len(after[day]) != len(before[day]) {
+				t.Fatalf("a swap changed day %d from %d to %d slots", day, len(before[day]), len(after[day]))
+			}
+			changed := 0
+			for slot := range before[day] {
+				if before[day][slot] != after[day][slot] {
+					changed++
+				}
+			}
+			if changed > 2 {
+				t.Fatalf("a swap turned day %d from %v into %v", day, before[day], after[day])
+			}
+		}
+	}
+
+	// A move takes a lesson to the end of another day, the lessons already there keep their slots
+	s = &Solver{MoveMutationRate: 1}
+	for range 100 {
+		before := subjects(ind)
+		s.mutate(rng, &ind, in, 1)
+		after := subjects(ind)
+		grown := 0
+		for day := range before {
+			if len(after[day]) > len(before[day]) {
+				grown++
+				if !slices.Equal(after[day][:len(before[day])], before[day]) {
+					t.Fatalf("a move into day %d turned %v into %v", day, before[day], after[day])
+				}
+			}
+		}
+		if grown > 1 {
+			t.Fatalf("a move added lessons to %d days", grown)
+		}
+		s.repair(&ind)
+	}
+}
+
+func TestMaxSlotsPerDay(t *testing.T) {
+	a := input.GlobalSubject("a")
+	in := input.InputData{Divisions: []input.Division{{Name: "A", MaxSlotsPerDay: 6, Subjects: []input.Subject{
+		{GlobalSubject: &a, Allocation: input.Allocation{8}},
+	}}}}
+	s := &Solver{}
+
+	long := week(lessons(&a, &a, &a, &a, &a, &a, &a, &a), nil, nil, nil, nil)
+	if v := s.hardViolations(long, in); v.LateLessons != 2 {
+		t.Errorf("8 hours in a day capped at 6 slots have %d late lessons, want 2", v.LateLessons)
+	}
+	capped := week(lessons(&a, &a, &a, &a, &a, &a), lessons(&a, &a), nil, nil, nil)
+	if late, ok := s.Evaluate(long, in), s.Evaluate(capped, in); late.LateLessons == 0 || late.Total() <= ok.Total() {
+		t.Errorf("8 hours in a day capped at 6 slots score %d, want a late lesson penalty and more than the %d within the cap", late.Total(), ok.Total())
+	}
+}
+
+func TestSolveDegenerate(t *testing.T) {
+	s := &Solver{PopulationSize: 4, Generations: 5, MutationRate: 0.2, Seed: 1}
+
+	if res := s.SolveContext(context.Background(), input.InputData{}); res.Err != nil || len(res.Output.DivisionsTimetables) != 0 {
+		t.Errorf("no divisions solved into %d timetables with error %v, want none without an error", len(res.Output.DivisionsTimetables), res.Err)
+	}
+
+	empty := input.InputData{Divisions: []input.Division{{Name: "1A"}}}
+	res := s.SolveContext(context.Background(), empty)
+	if res.Err != nil || len(res.Output.DivisionsTimetables) != 1 {
+		t.Fatalf("an empty division solved into %d timetables with error %v, want one without an error", len(res.Output.DivisionsTimetables), res.Err)
+	}
+	for day, d := range res.Output.DivisionsTimetables[0] {
+		for slot := range d {
+			if !d.IsFree(slot) {
+				t.Errorf("an empty division has a lesson on day %d, slot %d", day, slot)
+			}
+		}
+	}
+}
+
+func TestSolvePopulationTooSmall(t *testing.T) {
+	in := input.ExampleInputData
+	for _, size := range []int{0, 1} {
+		s := &Solver{PopulationSize: size, Generations: 5, MutationRate: 0.2, Seed: 1}
+
+		res := s.SolveContext(context.Background(), in)
+		if res.Err == nil || !strings.Contains(res.Err.Error(), "population size") {
+			t.Errorf("a population of %d solved with error %v, want a population size error", size, res.Err)
+		}
+		if len(res.Output.DivisionsTimetables) != len(in.Divisions) {
+			t.Errorf("a population of %d solved %d divisions, want an empty timetable for each of %d", size, len(res.Output.DivisionsTimetables), len(in.Divisions))
+		}
+		if all := s.SolveAll(in); len(all) != 0 {
+			t.Errorf("a population of %d ranked %d individuals, want none", size, len(all))
+		}
+		if _, err := s.ResumeFrom(strings.NewReader("{}"), in); err == nil {
+			t.Errorf("a population of %d resumed a checkpoint, want a population size error", size)
+		}
+	}
+}
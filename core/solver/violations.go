@@ -0,0 +1,153 @@
+// core/solver/violations.go
+package solver
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+// Violations describes every hard constraint violation of a solved timetable, e.g.
+// "Teacher LJ is booked 2 times on Tuesday slot 3", it explains a nonzero fitness
+// with what has to change in the input or the timetable. Violations between divisions
+// are reported per slot, the ones within a division per slot or per day.
+func (s *Solver) Violations(out output.OutputData, in input.InputData) []string {
+	var violations []string
+	timetables := out.DivisionsTimetables
+	usage := slotUsageOf(timetables)
+
+	weekDays, daySlots := 0, 0
+	for _, days := range timetables {
+		weekDays = max(weekDays, len(days))
+		for _, day := range days {
+			daySlots = max(daySlots, len(day))
+		}
+	}
+
+	for day := 0; day < weekDays; day++ {
+		for slot := 0; slot < daySlots; slot++ {
+			key := slotKey{day: day, slot: slot}
+			when := fmt.Sprintf("%s slot %d", output.DayName(day), slot+1)
+			for _, teacher := range slices.Sorted(maps.Keys(usage.teachers[key])) {
+				if n := usage.teachers[key][teacher]; n > 1 {
+					violations = append(violations, fmt.Sprintf("Teacher %s is booked %d times on %s", teacher, n, when))
+				}
+			}
+			for _, classroom := range slices.Sorted(maps.Keys(usage.classrooms[key])) {
+				if n := usage.classrooms[key][classroom]; n > 1 {
+					violations = append(violations, fmt.Sprintf("Classroom %s is booked %d times on %s", classroom, n, when))
+				}
+			}
+			for _, pair := range in.TeacherConflicts {
+				if usage.teachers[key][pair[0]] > 0 && usage.teachers[key][pair[1]] > 0 {
+					violations = append(violations, fmt.Sprintf("Conflicting teachers %s and %s both teach on %s", pair[0], pair[1], when))
+				}
+			}
+		}
+	}
+
+	for dIdx, div := range in.Divisions {
+		if dIdx >= len(timetables) {
+			break
+		}
+		violations = append(violations, s.divisionViolationList(timetables[dIdx], in, div, dIdx)...)
+	}
+	return violations
+}
+
+// divisionViolationList describes the hard constraint violations within a single division's timetable.
+func (s *Solver) divisionViolationList(days output.Days, in input.InputData, div input.Division, dIdx int) []string {
+	var violations []string
+	name := div.Name
+	if name == "" {
+		name = fmt.Sprintf("%d", dIdx)
+	}
+	add := func(format string, args ...any) {
+		violations = append(violations, fmt.Sprintf("Division %s: ", name)+fmt.Sprintf(format, args...))
+	}
+
+	// Unmet allocations, summed per subject
+	type subjectKey struct {
+		subject input.GlobalSubject
+		teacher input.Teacher
+	}
+	var order []subjectKey
+	unmet := make(map[subjectKey]int)
+	for _, c := range s.unmetChunks(days, div) {
+		if c.size == 0 {
+			continue
+		}
+		key := subjectKey{deref(c.subj.GlobalSubject), deref(c.subj.Teacher)}
+		if _, ok := unmet[key]; !ok {
+			order = append(order, key)
+		}
+		unmet[key] += int(c.size)
+	}
+	for _, key := range order {
+		add("%s is short by %d hours", key.subject, unmet[key])
+	}
+
+	capacities := make(map[string]uint, len(in.Classrooms))
+	for _, c := range in.Classrooms {
+		capacities[c.Name] = c.Capacity
+	}
+
+	for day, d := range days {
+		dayName := output.DayName(day)
+		for slot, sg := range d {
+			if slot < int(div.EarliestStart) && !sg.IsEmpty() {
+				add("lesson on %s slot %d is before the earliest start", dayName, slot+1)
+			}
+			if div.MaxSlotsPerDay > 0 && slot >= int(div.MaxSlotsPerDay) && !sg.IsEmpty() {
+				add("lesson on %s slot %d is after the last slot %d", dayName, slot+1, div.MaxSlotsPerDay)
+			}
+			if sharesWholeDivisionSlot(div, sg) {
+				add("whole division subject shares %s slot %d with other groups", dayName, slot+1)
+			}
+			if n := parallelClashes(sg); n > 0 {
+				add("%d parallel groups reuse a teacher or classroom on %s slot %d", n, dayName, slot+1)
+			}
+			if n := duplicateGroups(sg); n > 0 {
+				add("%d lessons of a group already taught on %s slot %d", n, dayName, slot+1)
+			}
+			if n := overfullLessons(sg); n > 0 {
+				add("%d lessons more than the %d parallel groups a slot holds on %s slot %d", n, output.MaxParallelGroups, dayName, slot+1)
+			}
+		}
+
+		// The counters take whole weeks, so they're given a week with just this day
+		single := make(output.Days, len(days))
+		single[day] = d
+		if n := s.divisionGaps(d); n > 0 {
+			add("%d empty slots between lessons on %s", n, dayName)
+		}
+		if s.LunchBreakHard && s.missesLunch(d) {
+			add("no lunch break on %s", dayName)
+		}
+		if n := splitBlocks(d); n > 0 {
+			add("%d blocks split across non-consecutive slots on %s", n, dayName)
+		}
+		if n := capacityOverflows(single, div, capacities); n > 0 {
+			add("%d hours with more students than fit into the classroom on %s", n, dayName)
+		}
+		if n := unavailableSlots(single, in.TeacherConstraints); n > 0 {
+			add("%d hours taught by unavailable teachers on %s", n, dayName)
+		}
+		for _, subj := range div.Subjects {
+			if !slices.Contains(subj.ForbiddenDays, day) {
+				continue
+			}
+			for _, sg := range d {
+				for _, placed := range sg {
+					if placed.GlobalSubject != nil && placedAs(placed, subj) {
+						add("%s is placed on its forbidden day %s", *placed.GlobalSubject, dayName)
+					}
+				}
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,22 @@
+// core/solver/violations_test.go
+package solver
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestViolations(t *testing.T) {
+	// LJ teaches every slot of the week in both divisions, so every slot has the clash
+	in := overbookedInput()
+	s := &Solver{PopulationSize: 10, Generations: 5, MutationRate: 0.1, Seed: 1}
+	res := s.SolveContext(context.Background(), in)
+
+	if res.Output.Fitness == 0 || len(res.Violations) == 0 {
+		t.Fatalf("fitness = %d with violations %q, want an infeasible timetable explained", res.Output.Fitness, res.Violations)
+	}
+	if want := "Teacher LJ is booked 2 times on Tuesday slot 3"; !slices.Contains(res.Violations, want) {
+		t.Errorf("violations = %q, want %q among them", res.Violations, want)
+	}
+}
@@ -0,0 +1,139 @@
+// core/solver/weights.go
+package solver
+
+// The penalty of a single violation of each fitness term, a weight of 0 disables its term
+type FitnessWeights struct {
+	// Hard constraints, per violation
+	TeacherOverlap   int `json:"teacher_overlap"`
+	ClassroomOverlap int `json:"classroom_overlap"`
+	UnmetHour        int `json:"unmet_hour"`
+	TeacherConflict  int `json:"teacher_conflict"`
+	ForbiddenDay     int `json:"forbidden_day"`
+	EarlyLesson      int `json:"early_lesson"`
+	SharedWholeSlot  int `json:"shared_whole_slot"`
+	ParallelClash    int `json:"parallel_clash"`
+	CapacityOverflow int `json:"capacity_overflow"`
+	DivisionGap      int `json:"division_gap"`
+	SplitBlock       int `json:"split_block"`
+	UnavailableSlot  int `json:"unavailable_slot"`
+	DuplicateGroup   int `json:"duplicate_group"`
+	LateLesson       int `json:"late_lesson"`
+	OverfullSlot     int `json:"overfull_slot"`
+
+	// Penalty per distinct subject above Solver.MaxSubjectsPerDay in a division's day
+	SubjectsPerDay int `json:"subjects_per_day"`
+	// Penalty per squared group of deviation from a division's mean daily load,
+	// or per group of difference between the busiest and the lightest day in threshold mode,
+	// multiplied by the division's weight
+	Imbalance int `json:"imbalance"`
+	// Penalty per slot a subject is placed away from its edges or center placement
+	Placement int `json:"placement"`
+	// Penalty per empty slot between a teacher's first and last lesson of a day,
+	// small compared to the hard constraints so it only breaks ties
+	TeacherGap int `json:"teacher_gap"`
+	// Penalty per day a teacher works below MinWorkDays or above MaxWorkDays
+	WorkDay int `json:"work_day"`
+	// Penalty per extra chunk of a subject placed on a day that already has one
+	RepeatedDay int `json:"repeated_day"`
+	// Penalty per empty leading slot after a division's earliest start, multiplied by its weight
+	LateStart int `json:"late_start"`
+	// Penalty per hour of a subject past its MaxConsecutive hours in a row
+	ConsecutiveHour int `json:"consecutive_hour"`
+	// Penalty per lesson placed outside its subject's preferred classrooms
+	PreferredClassroom int `json:"preferred_classroom"`
+	// Penalty per hour a teacher teaches above their MaxHoursPerDay or MaxHoursPerWeek
+	TeacherHour int `json:"teacher_hour"`
+	// Penalty per group a division's day with lessons is short of half its mean daily load
+	ShortDay int `json:"short_day"`
+	// Penalty per hour a teacher teaches a division outside their PreferredDivisions, except
+	// for the lessons of subjects the input pins the teacher to
+	PreferredDivision int `json:"preferred_division"`
+	// Penalty per chunk placed on a day outside its subject's PreferredDays
+	PreferredDay int `json:"preferred_day"`
+	// Penalty per division day booked solid through the lunch window, multiplied by the division's
+	// weight, a hard or a soft penalty depending on Solver.LunchBreakHard
+	LunchBreak int `json:"lunch_break"`
+	// Penalty per pair of consecutive slots a teacher teaches in classrooms of different buildings
+	BuildingChange int `json:"building_change"`
+	// Penalty per slot after its division's earliest start an hour of a difficult subject is
+	// taught, multiplied by the difficulty of its global subject
+	LateSubject int `json:"late_subject"`
+	// Penalty per run of lessons of a division's subject pair without its partner right next to it
+	SubjectPair int `json:"subject_pair"`
+	// Penalty per slot of spread between the blocks of subjects marked with SameTimeOfDay
+	TimeBand int `json:"time_band"`
+	// Penalty per lesson a division's day is busier than the day before it, rewarding weeks that
+	// get lighter towards Friday, when it's set it replaces the imbalance penalty since the two
+	// work against each other
+	FrontLoad int `json:"front_load"`
+}
+
+// The weights used when Solver.Weights isn't set, front-loading is disabled
+var DefaultFitnessWeights = FitnessWeights{
+	TeacherOverlap:   1000,
+	ClassroomOverlap: 1000,
+	UnmetHour:        500,
+	TeacherConflict:  1000,
+	ForbiddenDay:     1000,
+	EarlyLesson:      1000,
+	SharedWholeSlot:  1000,
+	ParallelClash:    1000,
+	CapacityOverflow: 1000,
+	DivisionGap:      1000,
+	SplitBlock:       1000,
+	UnavailableSlot:  1000,
+	DuplicateGroup:   1000,
+	LateLesson:       1000,
+	OverfullSlot:     1000,
+
+	SubjectsPerDay:     10,
+	Imbalance:          5,
+	Placement:          20,
+	TeacherGap:         10,
+	WorkDay:            50,
+	RepeatedDay:        50,
+	LateStart:          5,
+	ConsecutiveHour:    50,
+	PreferredClassroom: 20,
+	TeacherHour:        100,
+	ShortDay:           10,
+	PreferredDivision:  5,
+	PreferredDay:       20,
+	LunchBreak:         100,
+	BuildingChange:     30,
+	LateSubject:        5,
+	SubjectPair:        50,
+	TimeBand:           5,
+}
+
+// weights returns Solver.Weights, or DefaultFitnessWeights when it isn't set.
+func (s *Solver) weights() FitnessWeights {
+	if s.Weights != nil {
+		return *s.Weights
+	}
+	return DefaultFitnessWeights
+}
+
+func (w FitnessWeights) hasNegative() bool {
+	for _, f := range w.fields() {
+		if *f < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns every weight, in the order of declaration.
+func (w *FitnessWeights) fields() []*int {
+	return []*int{
+		&w.TeacherOverlap, &w.ClassroomOverlap, &w.UnmetHour, &w.TeacherConflict,
+		&w.ForbiddenDay, &w.EarlyLesson, &w.SharedWholeSlot, &w.ParallelClash,
+		&w.CapacityOverflow, &w.DivisionGap, &w.SplitBlock, &w.UnavailableSlot,
+		&w.DuplicateGroup, &w.LateLesson, &w.OverfullSlot,
+		&w.SubjectsPerDay, &w.Imbalance, &w.Placement, &w.TeacherGap,
+		&w.WorkDay, &w.RepeatedDay, &w.LateStart, &w.ConsecutiveHour,
+		&w.PreferredClassroom, &w.TeacherHour, &w.ShortDay, &w.PreferredDivision,
+		&w.PreferredDay, &w.LunchBreak, &w.BuildingChange, &w.LateSubject,
+		&w.SubjectPair, &w.TimeBand, &w.FrontLoad,
+	}
+}
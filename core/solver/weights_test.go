@@ -0,0 +1,31 @@
+// core/solver/weights_test.go
+package solver
+
+import (
+	"testing"
+
+	"smuggr.xyz/arrango/common/models/input"
+	"smuggr.xyz/arrango/common/models/output"
+)
+
+func TestWeights(t *testing.T) {
+	in := input.InputData{Divisions: []input.Division{{Name: "A"}}}
+	// A slightly unbalanced week against a balanced one starting three slots late on Monday
+	unbalanced, late := loads(5, 4, 4, 4, 3), loads(4, 4, 4, 4, 4)
+	late.Timetables[0][0] = append(make(output.Day, 3), late.Timetables[0][0]...)
+
+	prefersUnbalanced := func(s *Solver) bool {
+		return s.Evaluate(unbalanced, in).Total() < s.Evaluate(late, in).Total()
+	}
+	if !prefersUnbalanced(&Solver{}) {
+		t.Error("the late week is preferred with the default weights")
+	}
+	if prefersUnbalanced(weighted(func(w *FitnessWeights) { w.Imbalance *= 2 })) {
+		t.Error("the unbalanced week is still preferred with a doubled imbalance weight")
+	}
+
+	// A weight of 0 disables its term rather than falling back to the default
+	if p := weighted(func(w *FitnessWeights) { w.Imbalance = 0 }).imbalancePenalty(unbalanced, in); p != 0 {
+		t.Errorf("imbalance penalty = %d with a weight of 0, want 0", p)
+	}
+}